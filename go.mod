@@ -6,12 +6,14 @@ require (
 	github.com/apparentlymart/go-cidr v1.1.0
 	github.com/go-sql-driver/mysql v1.7.1
 	github.com/gosnmp/gosnmp v1.35.0
+	github.com/lib/pq v1.10.9
+	github.com/mattn/go-sqlite3 v1.14.17
 	github.com/praserx/ipconv v1.2.1
+	github.com/prometheus-community/pro-bing v0.2.0
 )
 
 require (
 	github.com/google/uuid v1.3.0 // indirect
-	github.com/prometheus-community/pro-bing v0.2.0 // indirect
 	golang.org/x/net v0.11.0 // indirect
 	golang.org/x/sync v0.2.0 // indirect
 	golang.org/x/sys v0.9.0 // indirect
@@ -3,49 +3,259 @@ package main
 import (
 	"as/camscan/internal/camscan/config"
 	"as/camscan/internal/camscan/database"
+	"as/camscan/internal/camscan/database/repository"
 	"as/camscan/internal/camscan/logging"
 	"as/camscan/internal/camscan/tasks"
+	"as/camscan/internal/camscan/types"
+	"as/camscan/internal/camscan/types/snmp"
+	"context"
 	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
 )
 
+const CommandDatabase = "db"
+const CommandDiscover = "discover"
+const CommandScan = "scan"
+const CommandTraps = "traps"
+
+const DatabaseActionBaseline = "baseline"
+const DatabaseActionMigrate = "migrate"
+const DatabaseActionSeed = "seed"
+const DatabaseActionStatus = "status"
+
+const TrapActionExport = "export"
+const TrapActionListen = "listen"
+
+var action = ""
+var command = CommandScan
+var daemon = false
 var debug = false
 var dryRun = false
-var initialized = false
+var interval time.Duration = 0
+var since = 24 * time.Hour
 var workers = 0
 
 func main() {
-	// Initialize program on first cycle execution
-	if initialized == false {
-		logging.Info("Initializing CamScan...")
-		initialize()
+	logging.Info("Initializing CamScan...")
+	initialize()
+
+	ctx := handleSignals()
+
+	switch command {
+	case CommandDatabase:
+		os.Exit(manageDatabase(action))
+	case CommandDiscover:
+		if success, _ := tasks.NewDiscoveryManager(config.AppConfig).Run(ctx); !success {
+			logging.Critical("Failed to discover devices.")
+			os.Exit(1)
+		}
+
+		logging.Info("CamScan has finished discovering devices.")
+	case CommandScan:
+		os.Exit(manageScans(ctx))
+	case CommandTraps:
+		os.Exit(manageTraps(ctx, action))
+	default:
+		logging.Critical("Unknown command; command: %s;", command)
+		os.Exit(2)
+	}
+}
+
+// handleSignals returns a context that is canceled on SIGINT or SIGTERM so that the running scan can stop gracefully.
+// A second signal terminates the program immediately.
+func handleSignals() context.Context {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+
+	go func() {
+		<-ctx.Done()
+		logging.Warning("Shutting down; waiting for running jobs to finish. Interrupt again to exit immediately.")
+		stop()
+	}()
+
+	return ctx
+}
+
+// manageScans runs a single scan, or a scan every scan interval in daemon mode, until the given context is canceled
+// and returns the program's exit code
+func manageScans(ctx context.Context) int {
+	manager := tasks.NewTaskManager(config.AppConfig)
+
+	// Invalid MIB files are reported at startup rather than by every scan cycle
+	if !manager.LoadMibs() {
+		logging.Critical("Failed to load the MIB files.")
+		return 1
 	}
 
 	for {
-		// Execute management process for task manager
-		if !tasks.ManageTasks() {
+		cycleStarted := time.Now()
+
+		success, summary := manager.Run(ctx)
+
+		if !success {
+			if config.AppConfig.Daemon == false {
+				logging.Critical("Failed to set up the scan.")
+				return 1
+			}
+
+			logging.Error("Failed to set up the scan; skipping scan cycle.")
+		}
+
+		if ctx.Err() != nil {
+			logging.Info("CamScan was stopped.")
+
+			// A single scan that didn't run every job is reported as a failure
+			if config.AppConfig.Daemon == false && summary.Status == snmp.ScanStatusPartial {
+				return 1
+			}
+
+			return 0
+		}
+
+		if config.AppConfig.Daemon == false {
 			logging.Info("CamScan has finished executing.")
-			break
+			return 0
+		}
+
+		// The next cycle only starts once the current one has finished so that cycles never overlap
+		elapsed := time.Since(cycleStarted)
+
+		if elapsed < config.AppConfig.ScanInterval {
+			logging.Info("Scan cycle finished in %v; starting the next cycle in %v.",
+				elapsed.Round(time.Second), (config.AppConfig.ScanInterval - elapsed).Round(time.Second))
+
+			select {
+			case <-time.After(config.AppConfig.ScanInterval - elapsed):
+			case <-ctx.Done():
+				logging.Info("CamScan was stopped.")
+				return 0
+			}
+		} else {
+			logging.Warning("Scan cycle took %v, which is longer than the scan interval of %v; "+
+				"starting the next cycle immediately.", elapsed.Round(time.Second), config.AppConfig.ScanInterval)
+		}
+	}
+}
+
+// manageTraps listens for traps until the given context is canceled, or exports the stored trap events, and returns
+// the program's exit code
+func manageTraps(ctx context.Context, action string) int {
+	manager := tasks.NewTrapManager(config.AppConfig)
+
+	switch action {
+	case "", TrapActionListen:
+		if !manager.Run(ctx) {
+			logging.Critical("Failed to receive SNMP traps.")
+			return 1
 		}
+
+		logging.Info("CamScan was stopped.")
+	case TrapActionExport:
+		if !manager.Export(since) {
+			logging.Critical("Failed to export SNMP trap events.")
+			return 1
+		}
+	default:
+		logging.Critical("Unknown trap action; action: %s; expected: %s or %s;",
+			action, TrapActionListen, TrapActionExport)
+		return 2
 	}
+
+	return 0
 }
 
 func initialize() {
 	// Define application arguments and allow for override of database environment settings
+	flag.BoolVar(&daemon, "daemon", daemon, "Determines whether scans are repeated every scan interval.")
 	flag.BoolVar(&debug, "debug", debug, "Determines whether debug mode is enabled.")
 	flag.BoolVar(&dryRun, "dry-run", dryRun, "Determines whether dry-run mode is enabled.")
+	flag.DurationVar(&interval, "interval", interval, "Defines the time between the starts of scans in daemon mode.")
+	flag.DurationVar(&since, "since", since, "Defines how far back the exported trap events go.")
 	flag.IntVar(&workers, "workers", workers, "Defines the number of workers to create.")
-	flag.Parse()
+
+	// Determine the command to execute when the first argument isn't a flag
+	arguments := os.Args[1:]
+	if len(arguments) > 0 && !strings.HasPrefix(arguments[0], "-") {
+		command = arguments[0]
+		arguments = arguments[1:]
+	}
+
+	// Determine the action of commands that take one, e.g. "camscan db migrate"
+	hasAction := command == CommandDatabase || command == CommandTraps
+	if hasAction && len(arguments) > 0 && !strings.HasPrefix(arguments[0], "-") {
+		action = arguments[0]
+		arguments = arguments[1:]
+	}
+
+	_ = flag.CommandLine.Parse(arguments)
 
 	// Load application settings from environment into structured configuration
-	appConfig := config.CreateAppConfig(workers, dryRun, debug)
+	appConfig := config.CreateAppConfig(workers, dryRun, debug, daemon, interval)
 	appConfig.DbConfig = database.CreateConfigFromEnvironment()
 	config.AppConfig = appConfig
 
 	// Configure the logging API
 	logging.SetLogLevel(appConfig.LogLevel)
+}
+
+// manageDatabase executes the given database maintenance action and returns the program's exit code
+func manageDatabase(action string) int {
+	if action != DatabaseActionMigrate && action != DatabaseActionSeed && action != DatabaseActionStatus &&
+		action != DatabaseActionBaseline {
+		logging.Critical("Unknown database action; action: %s; expected: %s, %s, %s or %s;",
+			action, DatabaseActionMigrate, DatabaseActionStatus, DatabaseActionSeed, DatabaseActionBaseline)
+		return 2
+	}
 
-	// Set up the task manager
-	tasks.SetupTaskManager()
+	// The schema check is skipped since these actions are how an out-of-date schema gets fixed
+	dbConfig := config.AppConfig.DbConfig
+	dbConfig.SkipSchemaCheck = true
+
+	success, db := database.OpenConnection(database.ConnectionMap.CamScan, dbConfig)
+
+	if success != true {
+		logging.Critical("Failed to connect to the database.")
+		return 1
+	}
+
+	defer database.CloseConnection(database.ConnectionMap.CamScan)
+
+	switch action {
+	case DatabaseActionBaseline:
+		success = database.Baseline(db, dbConfig.Driver)
+	case DatabaseActionMigrate:
+		success = database.Migrate(db, dbConfig.Driver)
+	case DatabaseActionSeed:
+		if err := database.CheckSchema(db, dbConfig.Driver); err != nil {
+			logging.Critical("Cannot seed the database; error: %s;", err.Error())
+			return 1
+		}
+
+		storage := database.GetRepositories(database.ConnectionMap.CamScan)
+		success = repository.SeedPollGroups(storage.PollGroups) && repository.SeedOidMaps(storage.OidMaps)
+	case DatabaseActionStatus:
+		var migrations []types.SchemaMigration
+		success, migrations = database.GetMigrationStatus(db, dbConfig.Driver)
+
+		for _, migration := range migrations {
+			status := "pending"
+
+			if migration.Applied > 0 {
+				status = "applied " + time.Unix(int64(migration.Applied), 0).Format(time.RFC3339)
+			}
+
+			fmt.Printf("%04d  %-32s %s\n", migration.Version, migration.Name, status)
+		}
+	}
+
+	if success != true {
+		logging.Critical("Database action failed; action: %s;", action)
+		return 1
+	}
 
-	initialized = true
+	return 0
 }
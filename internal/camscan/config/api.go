@@ -3,29 +3,60 @@ package config
 import (
 	"as/camscan/internal/camscan/logging"
 	"as/camscan/internal/camscan/types"
+	"as/camscan/internal/camscan/types/snmp"
 	"os"
 	"strconv"
 	"strings"
+	"time"
 )
 
+const DefaultJobTimeout = 60
+const DefaultRetryAttempts = 2
+const DefaultRetryBackoff = 5 * time.Second
+const DefaultScanInterval = 5 * time.Minute
+const DefaultSnmpMaxOids = 60
 const DefaultSnmpTimeout = 3
+const DefaultSubnetConcurrency = 4
+const DefaultTrapAddress = "0.0.0.0:162"
 const DefaultWorkers = 10
+const MaxRetryAttempts = 10
+const MinJobTimeout = 1
+const MinScanInterval = 10 * time.Second
 const MinSnmpTimeout = 0.1
 const MinWorkers = 1
+const SnmpCredentialEnvPrefix = "CAMS_SNMP_CREDENTIAL_"
+const SnmpNetworkEnvPrefix = "CAMS_SNMP_NETWORK_"
 
 var AppConfig types.AppConfig
 
-func CreateAppConfig(workers int, dryRun bool, debug bool) types.AppConfig {
+func CreateAppConfig(workers int, dryRun bool, debug bool, daemon bool, scanInterval time.Duration) types.AppConfig {
 	community := strings.Trim(os.Getenv("CAMS_COMMUNITY"), " ")
+	daemonEnv := strings.Trim(os.Getenv("CAMS_DAEMON"), " ")
 	debugEnv := strings.Trim(os.Getenv("CAMS_DEBUG"), " ")
 	dryRunEnv := strings.Trim(os.Getenv("CAMS_DRY_RUN"), " ")
 	icmpRetries, _ := strconv.Atoi(strings.Trim(os.Getenv("CAMS_ICMP_RETRIES"), " "))
 	icmpTimeout, _ := strconv.ParseFloat(strings.Trim(os.Getenv("CAMS_ICMP_TIMEOUT"), " "), 64)
+	jobTimeout, _ := strconv.ParseFloat(strings.Trim(os.Getenv("CAMS_JOB_TIMEOUT"), " "), 64)
 	logLevel, _ := strconv.Atoi(strings.Trim(os.Getenv("CAMS_LOG_LEVEL"), " "))
+	mibDir := strings.Trim(os.Getenv("CAMS_MIB_DIR"), " ")
+	packetsPerSecond, _ := strconv.Atoi(strings.Trim(os.Getenv("CAMS_PACKETS_PER_SECOND"), " "))
+	retryAttemptsEnv := strings.Trim(os.Getenv("CAMS_RETRY_ATTEMPTS"), " ")
+	retryBackoff, _ := time.ParseDuration(strings.Trim(os.Getenv("CAMS_RETRY_BACKOFF"), " "))
+	scanIntervalEnv, _ := time.ParseDuration(strings.Trim(os.Getenv("CAMS_SCAN_INTERVAL"), " "))
 	snmpApCommunity := strings.Trim(os.Getenv("CAMS_SNMP_AP_COMMUNITY"), " ")
+	snmpMaxOids, _ := strconv.Atoi(strings.Trim(os.Getenv("CAMS_SNMP_MAX_OIDS"), " "))
 	snmpSmCommunity := strings.Trim(os.Getenv("CAMS_SNMP_SM_COMMUNITY"), " ")
 	snmpTimeoutAp, _ := strconv.ParseFloat(strings.Trim(os.Getenv("CAMS_SNMP_TIMEOUT_AP"), " "), 64)
 	snmpTimeoutSm, _ := strconv.ParseFloat(strings.Trim(os.Getenv("CAMS_SNMP_TIMEOUT_SM"), " "), 64)
+	snmpV3AuthPassphrase := strings.Trim(os.Getenv("CAMS_SNMP_V3_AUTH_PASSPHRASE"), " ")
+	snmpV3AuthProtocol := strings.ToUpper(strings.Trim(os.Getenv("CAMS_SNMP_V3_AUTH_PROTOCOL"), " "))
+	snmpV3PrivPassphrase := strings.Trim(os.Getenv("CAMS_SNMP_V3_PRIV_PASSPHRASE"), " ")
+	snmpV3PrivProtocol := strings.ToUpper(strings.Trim(os.Getenv("CAMS_SNMP_V3_PRIV_PROTOCOL"), " "))
+	snmpV3User := strings.Trim(os.Getenv("CAMS_SNMP_V3_USER"), " ")
+	snmpVersion := NormalizeSnmpVersion(os.Getenv("CAMS_SNMP_VERSION"))
+	subnetConcurrencyEnv := strings.Trim(os.Getenv("CAMS_SUBNET_CONCURRENCY"), " ")
+	trapAddress := strings.Trim(os.Getenv("CAMS_TRAP_ADDRESS"), " ")
+	trapCommunities := make([]string, 0)
 	workersEnv, _ := strconv.Atoi(strings.Trim(os.Getenv("CAMS_WORKERS"), " "))
 
 	// Enforce minimum worker policy as well as assign default values
@@ -37,6 +68,26 @@ func CreateAppConfig(workers int, dryRun bool, debug bool) types.AppConfig {
 		community = "public"
 	}
 
+	if snmpApCommunity == "" {
+		snmpApCommunity = community
+	}
+
+	if snmpSmCommunity == "" {
+		snmpSmCommunity = community
+	}
+
+	if snmpMaxOids <= 0 {
+		snmpMaxOids = DefaultSnmpMaxOids
+	}
+
+	if snmpVersion == "" {
+		snmpVersion = snmp.Version2c
+	}
+
+	if len(daemonEnv) > 0 {
+		daemon, _ = strconv.ParseBool(daemonEnv)
+	}
+
 	if len(debugEnv) > 0 {
 		debug, _ = strconv.ParseBool(debugEnv)
 	}
@@ -49,6 +100,67 @@ func CreateAppConfig(workers int, dryRun bool, debug bool) types.AppConfig {
 		logLevel = logging.DefaultLogLevel
 	}
 
+	// The command line interval takes precedence over the environment
+	if scanInterval == 0 {
+		scanInterval = scanIntervalEnv
+	}
+
+	if scanInterval == 0 {
+		scanInterval = DefaultScanInterval
+	} else if scanInterval < MinScanInterval {
+		logging.Debug("Changing value for the 'interval' parameter from '%v' to '%v'", scanInterval, MinScanInterval)
+		scanInterval = MinScanInterval
+	}
+
+	if jobTimeout == 0 {
+		jobTimeout = DefaultJobTimeout
+	} else if jobTimeout < MinJobTimeout {
+		logging.Debug("Changing value for the 'JOB_TIMEOUT' setting from '%v' to '%v'", jobTimeout, MinJobTimeout)
+		jobTimeout = MinJobTimeout
+	}
+
+	// Failed polls are retried by default, unless retries are disabled by setting the attempts to zero
+	retryAttempts, err := strconv.Atoi(retryAttemptsEnv)
+
+	if len(retryAttemptsEnv) > 0 && (err != nil || retryAttempts < 0) {
+		logging.Warning("Ignoring invalid value '%s' for the 'RETRY_ATTEMPTS' setting; using '%v'", retryAttemptsEnv,
+			DefaultRetryAttempts)
+	}
+
+	if err != nil || retryAttempts < 0 {
+		retryAttempts = DefaultRetryAttempts
+	} else if retryAttempts > MaxRetryAttempts {
+		logging.Warning("Changing value for the 'RETRY_ATTEMPTS' setting from '%v' to '%v'", retryAttempts,
+			MaxRetryAttempts)
+		retryAttempts = MaxRetryAttempts
+	}
+
+	if retryBackoff <= 0 {
+		retryBackoff = DefaultRetryBackoff
+	}
+
+	// Subscriber modules of the same subnet are polled a few at a time by default, zero removes the limit
+	subnetConcurrency, err := strconv.Atoi(subnetConcurrencyEnv)
+
+	if err != nil || subnetConcurrency < 0 {
+		subnetConcurrency = DefaultSubnetConcurrency
+	}
+
+	if trapAddress == "" {
+		trapAddress = DefaultTrapAddress
+	}
+
+	// Traps are accepted from any community used for polling, unless the accepted communities are listed
+	for _, trapCommunity := range strings.Split(os.Getenv("CAMS_TRAP_COMMUNITIES"), ",") {
+		if trapCommunity = strings.Trim(trapCommunity, " "); trapCommunity != "" {
+			trapCommunities = append(trapCommunities, trapCommunity)
+		}
+	}
+
+	if packetsPerSecond < 0 {
+		packetsPerSecond = 0
+	}
+
 	if snmpTimeoutAp == 0 {
 		snmpTimeoutAp = DefaultSnmpTimeout
 	} else if snmpTimeoutAp < MinSnmpTimeout {
@@ -73,18 +185,153 @@ func CreateAppConfig(workers int, dryRun bool, debug bool) types.AppConfig {
 	}
 
 	config := types.AppConfig{
-		Community:       community,
-		Debug:           debug,
-		DryRun:          dryRun,
-		ICMPRetries:     icmpRetries,
-		ICMPTimeout:     icmpTimeout,
-		LogLevel:        logLevel,
-		SnmpApCommunity: snmpApCommunity,
-		SnmpSmCommunity: snmpSmCommunity,
-		SnmpTimeoutSm:   snmpTimeoutSm,
-		SnmpTimeoutAp:   snmpTimeoutAp,
-		Workers:         workers,
+		Community:            community,
+		Daemon:               daemon,
+		Debug:                debug,
+		DryRun:               dryRun,
+		ICMPRetries:          icmpRetries,
+		ICMPTimeout:          icmpTimeout,
+		JobTimeout:           jobTimeout,
+		LogLevel:             logLevel,
+		MibDir:               mibDir,
+		PacketsPerSecond:     packetsPerSecond,
+		RetryAttempts:        retryAttempts,
+		RetryBackoff:         retryBackoff,
+		ScanInterval:         scanInterval,
+		SnmpApCommunity:      snmpApCommunity,
+		SnmpCredentials:      CreateSnmpCredentials(),
+		SnmpMaxOids:          snmpMaxOids,
+		SnmpNetworks:         CreateSnmpNetworkCredentials(),
+		SnmpSmCommunity:      snmpSmCommunity,
+		SnmpTimeoutSm:        snmpTimeoutSm,
+		SnmpTimeoutAp:        snmpTimeoutAp,
+		SnmpV3AuthPassphrase: snmpV3AuthPassphrase,
+		SnmpV3AuthProtocol:   snmpV3AuthProtocol,
+		SnmpV3PrivPassphrase: snmpV3PrivPassphrase,
+		SnmpV3PrivProtocol:   snmpV3PrivProtocol,
+		SnmpV3User:           snmpV3User,
+		SnmpVersion:          snmpVersion,
+		SubnetConcurrency:    subnetConcurrency,
+		TrapAddress:          trapAddress,
+		TrapCommunities:      trapCommunities,
+		Workers:              workers,
 	}
 
 	return config
 }
+
+// CreateSnmpNetworkCredentials loads per-network SNMP overrides from environment variables named like
+// CAMS_SNMP_NETWORK_<NETWORK ID>_VERSION, CAMS_SNMP_NETWORK_<NETWORK ID>_COMMUNITY and
+// CAMS_SNMP_NETWORK_<NETWORK ID>_V3_USER. Settings that aren't defined for a network fall back to the global ones.
+func CreateSnmpNetworkCredentials() map[int]snmp.Credential {
+	credentials := make(map[int]snmp.Credential)
+
+	for _, variable := range os.Environ() {
+		name, value, found := strings.Cut(variable, "=")
+
+		if !found || !strings.HasPrefix(name, SnmpNetworkEnvPrefix) {
+			continue
+		}
+
+		networkIdEnv, setting, found := strings.Cut(strings.TrimPrefix(name, SnmpNetworkEnvPrefix), "_")
+		networkId, err := strconv.Atoi(networkIdEnv)
+
+		if !found || err != nil {
+			logging.Warning("Ignoring invalid SNMP network setting; name: %s;", name)
+			continue
+		}
+
+		credential := credentials[networkId]
+		credential.Name = "network-" + networkIdEnv
+
+		if !applySnmpCredentialSetting(&credential, setting, value) {
+			logging.Warning("Ignoring unknown SNMP network setting; name: %s;", name)
+			continue
+		}
+
+		credentials[networkId] = credential
+	}
+
+	return credentials
+}
+
+// CreateSnmpCredentials loads the ordered list of credential sets named by CAMS_SNMP_CREDENTIALS. Each set is defined
+// by environment variables named like CAMS_SNMP_CREDENTIAL_<NAME>_VERSION, CAMS_SNMP_CREDENTIAL_<NAME>_COMMUNITY and
+// CAMS_SNMP_CREDENTIAL_<NAME>_V3_USER.
+func CreateSnmpCredentials() []snmp.Credential {
+	credentials := make([]snmp.Credential, 0)
+	settings := []string{"VERSION", "COMMUNITY", "V3_USER", "V3_AUTH_PROTOCOL", "V3_AUTH_PASSPHRASE",
+		"V3_PRIV_PROTOCOL", "V3_PRIV_PASSPHRASE"}
+
+	for _, name := range strings.Split(os.Getenv("CAMS_SNMP_CREDENTIALS"), ",") {
+		name = strings.Trim(name, " ")
+
+		if name == "" {
+			continue
+		}
+
+		prefix := SnmpCredentialEnvPrefix + strings.ToUpper(strings.ReplaceAll(name, "-", "_")) + "_"
+		credential := snmp.Credential{Name: name}
+
+		for _, setting := range settings {
+			applySnmpCredentialSetting(&credential, setting, os.Getenv(prefix+setting))
+		}
+
+		if credential.Version == "" {
+			credential.Version = snmp.Version2c
+		}
+
+		if credential.Version == snmp.Version2c && credential.Community == "" {
+			logging.Warning("Ignoring SNMP credential set without a community; name: %s;", name)
+			continue
+		}
+
+		if credential.Version == snmp.Version3 && credential.User == "" {
+			logging.Warning("Ignoring SNMPv3 credential set without a user; name: %s;", name)
+			continue
+		}
+
+		credentials = append(credentials, credential)
+	}
+
+	return credentials
+}
+
+func applySnmpCredentialSetting(credential *snmp.Credential, setting string, value string) bool {
+	value = strings.Trim(value, " ")
+
+	switch setting {
+	case "VERSION":
+		credential.Version = NormalizeSnmpVersion(value)
+	case "COMMUNITY":
+		credential.Community = value
+	case "V3_USER":
+		credential.User = value
+	case "V3_AUTH_PROTOCOL":
+		credential.AuthProtocol = strings.ToUpper(value)
+	case "V3_AUTH_PASSPHRASE":
+		credential.AuthPassphrase = value
+	case "V3_PRIV_PROTOCOL":
+		credential.PrivProtocol = strings.ToUpper(value)
+	case "V3_PRIV_PASSPHRASE":
+		credential.PrivPassphrase = value
+	default:
+		return false
+	}
+
+	return true
+}
+
+func NormalizeSnmpVersion(version string) string {
+	switch strings.ToLower(strings.Trim(version, " ")) {
+	case "":
+		return ""
+	case "2", "2c", "v2", "v2c":
+		return snmp.Version2c
+	case "3", "v3":
+		return snmp.Version3
+	default:
+		logging.Warning("Changing unsupported SNMP version '%s' to '%s'", version, snmp.Version2c)
+		return snmp.Version2c
+	}
+}
@@ -1,18 +1,26 @@
 package database
 
 import (
+	"as/camscan/internal/camscan/database/repository"
 	"as/camscan/internal/camscan/logging"
 	"as/camscan/internal/camscan/types"
 	"database/sql"
+	"fmt"
 	_ "github.com/go-sql-driver/mysql"
+	_ "github.com/mattn/go-sqlite3"
 	"os"
 	"strconv"
 	"strings"
 	"time"
 )
 
+const DefaultBatchSize = 500
+const DefaultPostgresPort = "5432"
+const DefaultSQLitePath = "camscan.db"
+
 var configs = make(map[string]types.DbConfig)
 var connections = make(map[string]*sql.DB)
+var repositories = make(map[string]*repository.Repositories)
 var db *sql.DB = nil
 var dbConnectionString string
 var ConnectionMap = types.DbConnections{
@@ -40,12 +48,18 @@ func CreateConfigFromEnvironment() types.DbConfig {
 	var name = strings.Trim(os.Getenv("CAMS_DB_NAME"), " ")
 	var retries, _ = strconv.Atoi(strings.Trim(os.Getenv("CAMS_DB_CONNECT_RETRIEES"), " "))
 	var delay, _ = strconv.Atoi(strings.Trim(os.Getenv("CAMS_DB_CONNECT_RETRY_DELAY"), " "))
+	var batchSize, _ = strconv.Atoi(strings.Trim(os.Getenv("CAMS_DB_BATCH_SIZE"), " "))
+	var driver = strings.ToLower(strings.Trim(os.Getenv("CAMS_DB_DRIVER"), " "))
+	var path = strings.Trim(os.Getenv("CAMS_DB_PATH"), " ")
+	var sslMode = strings.Trim(os.Getenv("CAMS_DB_SSL_MODE"), " ")
 
 	if len(host) == 0 {
 		host = defaultConfig.Host
 	}
 
-	if len(port) == 0 {
+	if len(port) == 0 && driver == repository.DriverPostgres {
+		port = DefaultPostgresPort
+	} else if len(port) == 0 {
 		port = defaultConfig.Port
 	}
 
@@ -61,17 +75,38 @@ func CreateConfigFromEnvironment() types.DbConfig {
 		name = defaultConfig.Name
 	}
 
-	return CreateConfig(host, port, user, password, name, retries, delay)
+	var config = CreateConfig(host, port, user, password, name, retries, delay)
+
+	if batchSize > 0 {
+		config.BatchSize = batchSize
+	}
+
+	if len(driver) > 0 {
+		config.Driver = driver
+	}
+
+	if len(path) > 0 {
+		config.Path = path
+	}
+
+	if len(sslMode) > 0 {
+		config.SslMode = sslMode
+	}
+
+	return config
 }
 
 func GetDefaultConfig() types.DbConfig {
 	// Create a DbConfig instance populated with the program's default values
 	return types.DbConfig{
-		Host:     "localhost",
-		Name:     "camscan",
-		Password: "camscan",
-		Port:     "3306",
-		User:     "camscan",
+		BatchSize: DefaultBatchSize,
+		Driver:    repository.DriverMySQL,
+		Host:      "localhost",
+		Name:      "camscan",
+		Password:  "camscan",
+		Path:      DefaultSQLitePath,
+		Port:      "3306",
+		User:      "camscan",
 	}
 }
 
@@ -88,32 +123,106 @@ func CreateConnection(name string, config types.DbConfig) (bool, *sql.DB) {
 	// Cache a reference to the connection configuration
 	configs[name] = config
 
-	// Build the database connection string
-	dbConnectionString = config.User + ":" + config.Password +
-		"@(" + config.Host + ":" + config.Port + ")/" + config.Name
-
 	// Declare connection opening error variable
 	var dbOpenError error = nil
 
-	logging.Debug("Connecting to MySQL server; server: %s; port: %s; user: %s; name: %s;",
-		config.Host, config.Port, config.User, config.Name)
+	switch config.Driver {
+	case repository.DriverMySQL:
+		// Build the database connection string
+		dbConnectionString = config.User + ":" + config.Password +
+			"@(" + config.Host + ":" + config.Port + ")/" + config.Name
+
+		logging.Debug("Connecting to MySQL server; server: %s; port: %s; user: %s; name: %s;",
+			config.Host, config.Port, config.User, config.Name)
+
+		// Attempt to open connection to database
+		db, dbOpenError = sql.Open("mysql", dbConnectionString)
 
-	// Attempt to open connection to database
-	db, dbOpenError = sql.Open("mysql", dbConnectionString)
+		// Check for errors when opening database connection and handle accordingly
+		if dbOpenError != nil {
+			logging.Error("Error connecting to MySQL server; server: %s; port: %s; user: %s; name: %s; error: %s;",
+				config.Host, config.Port, config.User, config.Name, dbOpenError.Error())
+			return false, db
+		}
+	case repository.DriverPostgres:
+		dbConnectionString = fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s",
+			quoteConnectionValue(config.Host), quoteConnectionValue(config.Port), quoteConnectionValue(config.User),
+			quoteConnectionValue(config.Password), quoteConnectionValue(config.Name))
 
-	// Check for errors when opening database connection and handle accordingly
-	if dbOpenError != nil {
-		logging.Error("Error connecting to MySQL server; server: %s; port: %s; user: %s; name: %s; error: %s;",
-			config.Host, config.Port, config.User, config.Name, dbOpenError.Error())
+		if config.SslMode != "" {
+			dbConnectionString += " sslmode=" + quoteConnectionValue(config.SslMode)
+		}
+
+		logging.Debug("Connecting to PostgreSQL server; server: %s; port: %s; user: %s; name: %s;",
+			config.Host, config.Port, config.User, config.Name)
+
+		db, dbOpenError = sql.Open(postgresDriverName, dbConnectionString)
+
+		if dbOpenError != nil {
+			logging.Error("Error connecting to PostgreSQL server; server: %s; port: %s; user: %s; name: %s; "+
+				"error: %s;", config.Host, config.Port, config.User, config.Name, dbOpenError.Error())
+			return false, db
+		}
+	case repository.DriverSQLite:
+		// Wait for locks held by other writers instead of failing immediately
+		dbConnectionString = "file:" + config.Path + "?_busy_timeout=5000"
+
+		logging.Debug("Opening SQLite database; path: %s;", config.Path)
+
+		db, dbOpenError = sql.Open("sqlite3", dbConnectionString)
+
+		if dbOpenError != nil {
+			logging.Error("Error opening SQLite database; path: %s; error: %s;", config.Path, dbOpenError.Error())
+			return false, db
+		}
+
+		// SQLite only allows a single writer, so workers share one connection rather than contend for the lock
+		db.SetMaxOpenConns(1)
+	default:
+		logging.Error("Unsupported database driver; driver: %s;", config.Driver)
+		return false, nil
+	}
+
+	// Refuse to use a database whose schema hasn't been migrated to the version this program expects
+	if config.SkipSchemaCheck == false {
+		if schemaError := CheckSchema(db, config.Driver); schemaError != nil {
+			logging.Error("Database schema check failed; driver: %s; name: %s; error: %s;",
+				config.Driver, describeConfig(config), schemaError.Error())
+			_ = db.Close()
+			return false, db
+		}
+	}
+
+	storage, repositoryError := repository.New(config.Driver, db)
+
+	if repositoryError != nil {
+		logging.Error("Failed to create database repositories; driver: %s; error: %s;",
+			config.Driver, repositoryError.Error())
+		_ = db.Close()
 		return false, db
 	}
 
-	// Cache a reference to the connection
+	// Cache a reference to the connection and its repositories
 	connections[name] = db
+	repositories[name] = storage
 
 	return true, db
 }
 
+// quoteConnectionValue quotes a value of a PostgreSQL key/value connection string
+func quoteConnectionValue(value string) string {
+	return "'" + strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(value) + "'"
+}
+
+// describeConfig returns the name used in log messages for the database of the given configuration
+func describeConfig(config types.DbConfig) string {
+	if config.Driver == repository.DriverSQLite {
+		return config.Path
+	}
+
+	return config.Host + ":" + config.Port + "/" + config.Name
+}
+
 func OpenConnection(name string, config types.DbConfig) (bool, *sql.DB) {
 	var db *sql.DB
 	opened := false
@@ -151,14 +260,12 @@ func CloseConnection(name string) bool {
 	err := db.Close()
 
 	if err != nil {
-		logging.Error("Error closing connection to MySQL database server; "+
-			"server: %s; port: %s; user: %s; name: %s; error: %s;",
-			dbConfig.Host, dbConfig.Port, dbConfig.User, dbConfig.Name, err.Error())
+		logging.Error("Error closing database connection; driver: %s; name: %s; error: %s;",
+			dbConfig.Driver, describeConfig(dbConfig), err.Error())
 		return false
 	} else {
-		logging.Debug("Closed connection to MySQL database server; "+
-			"server: %s; port: %s; user: %s; name: %s;",
-			dbConfig.Host, dbConfig.Port, dbConfig.User, dbConfig.Name)
+		logging.Debug("Closed database connection; driver: %s; name: %s;",
+			dbConfig.Driver, describeConfig(dbConfig))
 	}
 
 	return true
@@ -168,12 +275,18 @@ func GetConnection(name string) *sql.DB {
 	return connections[name]
 }
 
+// GetRepositories returns the storage repositories of the given connection, or nil when it isn't connected.
+func GetRepositories(name string) *repository.Repositories {
+	return repositories[name]
+}
+
 func RemoveConnection(name string) bool {
 	// Verify that the given connection name is already registered or return false otherwise
 	if HasConnection(name) == false {
 		return false
 	}
-	// Delete the connection reference from the map
+	// Delete the connection and repository references from the maps
 	delete(connections, name)
+	delete(repositories, name)
 	return true
 }
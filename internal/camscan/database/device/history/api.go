@@ -0,0 +1,78 @@
+package history
+
+import (
+	"as/camscan/internal/camscan/logging"
+	"as/camscan/internal/camscan/types/device"
+	"database/sql"
+)
+
+func GetRecords(db *sql.DB, deviceType int, deviceId int) (bool, []device.AddressChange) {
+	var records []device.AddressChange
+	var sqlQuery = `SELECT id, device_type, device_id, mac_address, old_ipv4_address, old_ipv4_address_int,
+       				new_ipv4_address, new_ipv4_address_int, changed
+					FROM device_address_history
+					WHERE device_type = ? AND device_id = ?
+					ORDER BY changed, id`
+
+	sqlResults, sqlError := db.Query(sqlQuery, deviceType, deviceId)
+
+	if sqlError != nil {
+		logging.Error("Error retrieving device address history records from database; type: %v; id: %v; error: %s;",
+			deviceType, deviceId, sqlError.Error())
+		return false, records
+	}
+
+	defer func(sqlResults *sql.Rows) {
+		_ = sqlResults.Close()
+	}(sqlResults)
+
+	for sqlResults.Next() {
+		var record device.AddressChange
+		_ = sqlResults.Scan(&record.Id, &record.DeviceType, &record.DeviceId, &record.MacAddress,
+			&record.OldIPv4Address, &record.OldIPv4AddressInt, &record.NewIPv4Address, &record.NewIPv4AddressInt,
+			&record.Changed)
+
+		records = append(records, record)
+
+		logging.Trace1("Device address history record loaded; "+
+			"id: %v; type: %v; did: %v; mac: %s; old: %s; new: %s; changed: %v;",
+			record.Id, record.DeviceType, record.DeviceId, record.MacAddress, record.OldIPv4Address,
+			record.NewIPv4Address, record.Changed)
+	}
+
+	return true, records
+}
+
+func InsertRecord(db *sql.DB, record device.AddressChange) (bool, device.AddressChange) {
+	sqlQuery := `INSERT INTO device_address_history(device_type, device_id, mac_address, old_ipv4_address,
+                                   old_ipv4_address_int, new_ipv4_address, new_ipv4_address_int, changed)
+			     VALUES (?, ?, ?, ?, ?, ?, ?, ?)`
+
+	sqlResult, sqlError := db.Exec(sqlQuery,
+		record.DeviceType,
+		record.DeviceId,
+		record.MacAddress,
+		record.OldIPv4Address,
+		record.OldIPv4AddressInt,
+		record.NewIPv4Address,
+		record.NewIPv4AddressInt,
+		record.Changed,
+	)
+
+	if sqlError != nil {
+		logging.Error("Failed to create device address history record; "+
+			"type: %v; did: %v; mac: %s; old: %s; new: %s; changed: %v; error: %s;",
+			record.DeviceType, record.DeviceId, record.MacAddress, record.OldIPv4Address, record.NewIPv4Address,
+			record.Changed, sqlError.Error())
+		return false, record
+	}
+
+	if id, err := sqlResult.LastInsertId(); err == nil {
+		record.Id = int(id)
+	}
+
+	logging.Debug("Device address change recorded; type: %v; did: %v; mac: %s; old: %s; new: %s;",
+		record.DeviceType, record.DeviceId, record.MacAddress, record.OldIPv4Address, record.NewIPv4Address)
+
+	return true, record
+}
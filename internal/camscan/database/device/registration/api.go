@@ -0,0 +1,58 @@
+package registration
+
+import (
+	"as/camscan/internal/camscan/logging"
+	"as/camscan/internal/camscan/types/device"
+	"database/sql"
+)
+
+func GetRecords(db *sql.DB) (bool, []device.Registration) {
+	var records []device.Registration
+	var sqlQuery = `SELECT subscriber_module_id, access_point_id, updated
+					FROM device_registration`
+
+	sqlResults, sqlError := db.Query(sqlQuery)
+
+	if sqlError != nil {
+		logging.Error("Error retrieving device registration records from database; error: %s;", sqlError.Error())
+		return false, records
+	}
+
+	defer func(sqlResults *sql.Rows) {
+		_ = sqlResults.Close()
+	}(sqlResults)
+
+	for sqlResults.Next() {
+		var record device.Registration
+		_ = sqlResults.Scan(&record.SubscriberModuleId, &record.AccessPointId, &record.Updated)
+
+		records = append(records, record)
+
+		logging.Trace1("Device registration record loaded; sm: %v; ap: %v; updated: %v;",
+			record.SubscriberModuleId, record.AccessPointId, record.Updated)
+	}
+
+	return true, records
+}
+
+func UpsertRecord(db *sql.DB, record device.Registration) (bool, device.Registration) {
+	sqlQuery := `INSERT INTO device_registration(subscriber_module_id, access_point_id, updated)
+			     VALUES (?, ?, ?)
+				 ON DUPLICATE KEY UPDATE access_point_id=?, updated=?`
+
+	_, sqlError := db.Exec(sqlQuery,
+		record.SubscriberModuleId,
+		record.AccessPointId,
+		record.Updated,
+		record.AccessPointId,
+		record.Updated,
+	)
+
+	if sqlError != nil {
+		logging.Error("Failed to create device registration record; sm: %v; ap: %v; error: %s;",
+			record.SubscriberModuleId, record.AccessPointId, sqlError.Error())
+		return false, record
+	}
+
+	return true, record
+}
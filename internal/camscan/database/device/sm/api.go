@@ -1,14 +1,17 @@
 package ap
 
 import (
+	dbDevice "as/camscan/internal/camscan/database/device"
 	"as/camscan/internal/camscan/logging"
 	"as/camscan/internal/camscan/types/device"
+	"as/camscan/internal/camscan/types/snmp"
 	"database/sql"
 )
 
 func GetRecords(db *sql.DB) (bool, []device.SubscriberModule) {
 	var records []device.SubscriberModule
-	var sqlQuery = `SELECT id, network_id, mac_address, ipv4_address, ipv4_address_int, status
+	var sqlQuery = `SELECT id, network_id, mac_address, ipv4_address, ipv4_address_int, snmp_credential, snmp_status,
+       				status
 					FROM device_subscriber_module`
 
 	sqlResults, sqlError := db.Query(sqlQuery)
@@ -21,7 +24,7 @@ func GetRecords(db *sql.DB) (bool, []device.SubscriberModule) {
 		for sqlResults.Next() {
 			var record device.SubscriberModule
 			_ = sqlResults.Scan(&record.Id, &record.NetworkId, &record.MacAddress, &record.IPv4Address,
-				&record.IPv4AddressInt, &record.Status)
+				&record.IPv4AddressInt, &record.SnmpCredential, &record.SnmpStatus, &record.Status)
 
 			records = append(records, record)
 
@@ -35,31 +38,66 @@ func GetRecords(db *sql.DB) (bool, []device.SubscriberModule) {
 	return true, records
 }
 
-func UpsertRecord(db *sql.DB, record device.SubscriberModule) (bool, device.SubscriberModule) {
-	sqlQuery := `INSERT INTO device_subscriber_module(network_id, mac_address, ipv4_address, ipv4_address_int, status)
-			     VALUES (?, ?, ?, ?, ?)
-				 ON DUPLICATE KEY UPDATE network_id=?, mac_address=?, ipv4_address=?, ipv4_address_int=?, status=?`
+func GetRecordByMacAddress(db *sql.DB, mac string) (bool, device.SubscriberModule) {
+	return getRecordByField(db, "mac_address", mac)
+}
 
-	insertStmt, sqlError := db.Prepare(sqlQuery)
+func GetRecordByIPv4Address(db *sql.DB, ipv4 string) (bool, device.SubscriberModule) {
+	return getRecordByField(db, "ipv4_address", ipv4)
+}
 
-	if sqlError != nil {
-		logging.Error("Failed to create subscriber module record; "+
-			"id: %v; nid: %v; mac: %s; ipv4: %s; ipv4int: %v; status: %v; error: %s;",
+func getRecordByField(db *sql.DB, field string, value interface{}) (bool, device.SubscriberModule) {
+	var record device.SubscriberModule
+	var sqlQuery = `SELECT id, network_id, mac_address, ipv4_address, ipv4_address_int, snmp_credential, snmp_status,
+       				status
+					FROM device_subscriber_module
+					WHERE ` + field + ` = ?
+					ORDER BY id
+					LIMIT 1`
+
+	row := db.QueryRow(sqlQuery, value)
+
+	sqlError := row.Scan(&record.Id, &record.NetworkId, &record.MacAddress, &record.IPv4Address,
+		&record.IPv4AddressInt, &record.SnmpCredential, &record.SnmpStatus, &record.Status)
+
+	switch sqlError {
+	case nil:
+		logging.Trace1("Subscriber module record loaded; "+
+			"id: %v; nid: %v; mac: %s; ipv4: %s; ipv4int: %v; status: %v;",
 			record.Id, record.NetworkId, record.MacAddress, record.IPv4Address, record.IPv4AddressInt,
-			record.Status, sqlError.Error())
+			record.Status)
+		return true, record
+	case sql.ErrNoRows:
+		return false, record
+	default:
+		logging.Error("Error retrieving subscriber module record from database; field: %s; value: %v; error: %s;",
+			field, value, sqlError.Error())
 		return false, record
 	}
+}
 
-	_, sqlError = insertStmt.Exec(
-		record.NetworkId,
-		record.MacAddress,
-		record.IPv4Address,
-		record.IPv4AddressInt,
-		record.Status,
+// UpsertRecord stores the given subscriber module using its MAC address as the device identity, see
+// device.UpsertRecord.
+func UpsertRecord(db *sql.DB, record device.SubscriberModule,
+	skipKnownMac string) (bool, device.SubscriberModule) {
+	success, stored := dbDevice.UpsertRecord(db, "device_subscriber_module", snmp.DeviceTypeSubscriberModule,
+		device.AccessPoint(record), skipKnownMac)
+
+	return success, device.SubscriberModule(stored)
+}
+
+func InsertRecord(db *sql.DB, record device.SubscriberModule) (bool, device.SubscriberModule) {
+	sqlQuery := `INSERT INTO device_subscriber_module(network_id, mac_address, ipv4_address, ipv4_address_int, snmp_credential,
+                                   snmp_status, status)
+			     VALUES (?, ?, ?, ?, ?, ?, ?)`
+
+	sqlResult, sqlError := db.Exec(sqlQuery,
 		record.NetworkId,
 		record.MacAddress,
 		record.IPv4Address,
 		record.IPv4AddressInt,
+		record.SnmpCredential,
+		record.SnmpStatus,
 		record.Status,
 	)
 
@@ -71,9 +109,32 @@ func UpsertRecord(db *sql.DB, record device.SubscriberModule) (bool, device.Subs
 		return false, record
 	}
 
-	sqlError = insertStmt.Close()
+	if id, err := sqlResult.LastInsertId(); err == nil {
+		record.Id = int(id)
+	}
+
+	return true, record
+}
+
+func UpdateRecord(db *sql.DB, record device.SubscriberModule) (bool, device.SubscriberModule) {
+	sqlQuery := `UPDATE device_subscriber_module
+				 SET network_id=?, mac_address=?, ipv4_address=?, ipv4_address_int=?, snmp_credential=?, snmp_status=?,
+				     status=?
+				 WHERE id = ?`
+
+	_, sqlError := db.Exec(sqlQuery,
+		record.NetworkId,
+		record.MacAddress,
+		record.IPv4Address,
+		record.IPv4AddressInt,
+		record.SnmpCredential,
+		record.SnmpStatus,
+		record.Status,
+		record.Id,
+	)
+
 	if sqlError != nil {
-		logging.Warning("Failed to close MySQL prepared statement for subscriber module; "+
+		logging.Error("Failed to update subscriber module record; "+
 			"id: %v; nid: %v; mac: %s; ipv4: %s; ipv4int: %v; status: %v; error: %s;",
 			record.Id, record.NetworkId, record.MacAddress, record.IPv4Address, record.IPv4AddressInt,
 			record.Status, sqlError.Error())
@@ -82,3 +143,20 @@ func UpsertRecord(db *sql.DB, record device.SubscriberModule) (bool, device.Subs
 
 	return true, record
 }
+
+func UpdateSnmpStatus(db *sql.DB, record device.SubscriberModule) bool {
+	sqlQuery := `UPDATE device_subscriber_module
+				 SET snmp_credential=?, snmp_status=?
+				 WHERE id = ?`
+
+	_, sqlError := db.Exec(sqlQuery, record.SnmpCredential, record.SnmpStatus, record.Id)
+
+	if sqlError != nil {
+		logging.Error("Failed to update SNMP status of subscriber module record; "+
+			"id: %v; ipv4: %s; credential: %s; status: %v; error: %s;",
+			record.Id, record.IPv4Address, record.SnmpCredential, record.SnmpStatus, sqlError.Error())
+		return false
+	}
+
+	return true
+}
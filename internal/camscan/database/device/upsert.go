@@ -0,0 +1,204 @@
+package device
+
+import (
+	"as/camscan/internal/camscan/database/device/history"
+	"as/camscan/internal/camscan/logging"
+	deviceTypes "as/camscan/internal/camscan/types/device"
+	"database/sql"
+	"time"
+)
+
+// Columns lists the columns that the access point and subscriber module tables share, besides their ID
+const Columns = "network_id, mac_address, ipv4_address, ipv4_address_int, snmp_credential, snmp_status, status"
+
+// UpsertRecord stores the given device in the given table using its MAC address as the device identity. Access points
+// and subscriber modules share the same columns, so subscriber modules are passed in as access points. When a known
+// MAC address is found at a new IPv4 address, the existing record is updated in place and the address change is
+// recorded. New devices are inserted with skipKnownMac, a clause in the database's dialect that skips the insert when
+// the unique index of known MAC addresses already holds the MAC address, so that a device stored by another worker
+// after the lookup is updated rather than stored twice. Other records at the address of a device with a known MAC
+// address are retired, see RetireReplacedRecords.
+func UpsertRecord(db *sql.DB, table string, deviceType int, record deviceTypes.AccessPoint,
+	skipKnownMac string) (bool, deviceTypes.AccessPoint) {
+	var found bool
+	var existing deviceTypes.AccessPoint
+
+	known := record.MacAddress != "" && record.MacAddress != deviceTypes.UnknownMacAddress
+
+	if known {
+		found, existing = getRecordByField(db, table, "mac_address", record.MacAddress)
+	}
+
+	// Fall back to a record that has yet to be identified by its MAC address
+	if !found {
+		found, existing = getRecordByField(db, table, "ipv4_address", record.IPv4Address)
+		found = found &&
+			(existing.MacAddress == deviceTypes.UnknownMacAddress || existing.MacAddress == record.MacAddress)
+	}
+
+	if !found {
+		success, inserted, stored := insertUnlessKnown(db, table, record, skipKnownMac)
+
+		if success != true {
+			return false, record
+		}
+
+		record = stored
+
+		if !inserted {
+			found, existing = getRecordByField(db, table, "mac_address", record.MacAddress)
+
+			if !found {
+				logging.Error("Failed to load device record stored concurrently; table: %s; mac: %s; ipv4: %s;",
+					table, record.MacAddress, record.IPv4Address)
+				return false, record
+			}
+		}
+	}
+
+	if found {
+		record.Id = existing.Id
+
+		if !known {
+			record.MacAddress = existing.MacAddress
+		}
+
+		if success := updateRecord(db, table, record); success != true {
+			return false, record
+		}
+
+		if existing.IPv4Address != record.IPv4Address {
+			history.InsertRecord(db, deviceTypes.AddressChange{
+				DeviceType:        deviceType,
+				DeviceId:          record.Id,
+				MacAddress:        record.MacAddress,
+				OldIPv4Address:    existing.IPv4Address,
+				OldIPv4AddressInt: existing.IPv4AddressInt,
+				NewIPv4Address:    record.IPv4Address,
+				NewIPv4AddressInt: record.IPv4AddressInt,
+				Changed:           int(time.Now().Unix()),
+			})
+		}
+	}
+
+	if known {
+		RetireReplacedRecords(db, table, record)
+	}
+
+	return true, record
+}
+
+// RetireReplacedRecords marks every other active record at the address of the given device as inactive, since the
+// devices they describe were replaced by the given one, which keeps scans from polling them at an address that now
+// belongs to another device. A retired device that shows up again is reactivated by its next upsert.
+func RetireReplacedRecords(db *sql.DB, table string, record deviceTypes.AccessPoint) bool {
+	sqlQuery := `UPDATE ` + table + `
+				 SET status = 0
+				 WHERE ipv4_address = ? AND id <> ? AND status > 0`
+
+	sqlResult, sqlError := db.Exec(sqlQuery, record.IPv4Address, record.Id)
+
+	if sqlError != nil {
+		logging.Error("Failed to retire replaced device records; table: %s; id: %v; ipv4: %s; error: %s;",
+			table, record.Id, record.IPv4Address, sqlError.Error())
+		return false
+	}
+
+	if rows, err := sqlResult.RowsAffected(); err == nil && rows > 0 {
+		logging.Info("Retired device records replaced by another device; table: %s; id: %v; mac: %s; ipv4: %s; "+
+			"records: %v;", table, record.Id, record.MacAddress, record.IPv4Address, rows)
+	}
+
+	return true
+}
+
+func getRecordByField(db *sql.DB, table string, field string, value interface{}) (bool, deviceTypes.AccessPoint) {
+	var record deviceTypes.AccessPoint
+	var sqlQuery = `SELECT id, ` + Columns + `
+					FROM ` + table + `
+					WHERE ` + field + ` = ?
+					ORDER BY id
+					LIMIT 1`
+
+	sqlError := db.QueryRow(sqlQuery, value).Scan(&record.Id, &record.NetworkId, &record.MacAddress,
+		&record.IPv4Address, &record.IPv4AddressInt, &record.SnmpCredential, &record.SnmpStatus, &record.Status)
+
+	switch sqlError {
+	case nil:
+		return true, record
+	case sql.ErrNoRows:
+		return false, record
+	default:
+		logging.Error("Error retrieving device record from database; table: %s; field: %s; value: %v; error: %s;",
+			table, field, value, sqlError.Error())
+		return false, record
+	}
+}
+
+// insertUnlessKnown inserts the given device unless the unique index of known MAC addresses already holds its MAC
+// address, in which case false is returned as the second value
+func insertUnlessKnown(db *sql.DB, table string, record deviceTypes.AccessPoint,
+	skipKnownMac string) (bool, bool, deviceTypes.AccessPoint) {
+	sqlQuery := `INSERT INTO ` + table + `(` + Columns + `)
+			     VALUES (?, ?, ?, ?, ?, ?, ?)
+			     ` + skipKnownMac
+
+	sqlResult, sqlError := db.Exec(sqlQuery,
+		record.NetworkId,
+		record.MacAddress,
+		record.IPv4Address,
+		record.IPv4AddressInt,
+		record.SnmpCredential,
+		record.SnmpStatus,
+		record.Status,
+	)
+
+	if sqlError != nil {
+		logging.Error("Failed to create device record; "+
+			"table: %s; nid: %v; mac: %s; ipv4: %s; ipv4int: %v; status: %v; error: %s;",
+			table, record.NetworkId, record.MacAddress, record.IPv4Address, record.IPv4AddressInt, record.Status,
+			sqlError.Error())
+		return false, false, record
+	}
+
+	// The insert was skipped, which leaves the last insert ID of the connection unchanged
+	if rows, err := sqlResult.RowsAffected(); err == nil && rows == 0 {
+		logging.Debug("Skipped insert of device stored concurrently; table: %s; mac: %s; ipv4: %s;",
+			table, record.MacAddress, record.IPv4Address)
+		return true, false, record
+	}
+
+	if id, err := sqlResult.LastInsertId(); err == nil {
+		record.Id = int(id)
+	}
+
+	return true, true, record
+}
+
+func updateRecord(db *sql.DB, table string, record deviceTypes.AccessPoint) bool {
+	sqlQuery := `UPDATE ` + table + `
+				 SET network_id=?, mac_address=?, ipv4_address=?, ipv4_address_int=?, snmp_credential=?, snmp_status=?,
+				     status=?
+				 WHERE id = ?`
+
+	_, sqlError := db.Exec(sqlQuery,
+		record.NetworkId,
+		record.MacAddress,
+		record.IPv4Address,
+		record.IPv4AddressInt,
+		record.SnmpCredential,
+		record.SnmpStatus,
+		record.Status,
+		record.Id,
+	)
+
+	if sqlError != nil {
+		logging.Error("Failed to update device record; "+
+			"table: %s; id: %v; nid: %v; mac: %s; ipv4: %s; ipv4int: %v; status: %v; error: %s;",
+			table, record.Id, record.NetworkId, record.MacAddress, record.IPv4Address, record.IPv4AddressInt,
+			record.Status, sqlError.Error())
+		return false
+	}
+
+	return true
+}
@@ -0,0 +1,88 @@
+package database
+
+import (
+	"as/camscan/internal/camscan/database/repository"
+	"as/camscan/internal/camscan/types/device"
+	"database/sql"
+	"path/filepath"
+	"testing"
+)
+
+func migratedRepositories(t *testing.T) *repository.Repositories {
+	db, err := sql.Open("sqlite3", filepath.Join(t.TempDir(), "camscan.db"))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() {
+		_ = db.Close()
+	})
+
+	if !Migrate(db, repository.DriverSQLite) {
+		t.Fatal("migration failed")
+	}
+
+	repositories, err := repository.New(repository.DriverSQLite, db)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return repositories
+}
+
+func TestUpsertRecordRetiresReplacedDevice(t *testing.T) {
+	repositories := migratedRepositories(t)
+	accessPoints := repositories.AccessPoints
+
+	_, replaced := accessPoints.UpsertRecord(device.AccessPoint{NetworkId: 1, MacAddress: "0a003eaabbcc",
+		IPv4Address: "10.0.0.5", IPv4AddressInt: 0x0a000005, Status: 1})
+	_, replacement := accessPoints.UpsertRecord(device.AccessPoint{NetworkId: 1, MacAddress: "0a003eaabbdd",
+		IPv4Address: "10.0.0.5", IPv4AddressInt: 0x0a000005, Status: 1})
+
+	if replacement.Id == replaced.Id {
+		t.Fatalf("replacement stored as the replaced device; id: %v", replacement.Id)
+	}
+
+	if _, record := accessPoints.GetRecordByMacAddress("0a003eaabbcc"); record.Status != 0 {
+		t.Errorf("replaced device status = %v, want 0", record.Status)
+	}
+
+	if _, record := accessPoints.GetRecordByMacAddress("0a003eaabbdd"); record.Status != 1 {
+		t.Errorf("replacement status = %v, want 1", record.Status)
+	}
+
+	// The replaced device is active again once it shows up at another address
+	_, moved := accessPoints.UpsertRecord(device.AccessPoint{NetworkId: 1, MacAddress: "0a003eaabbcc",
+		IPv4Address: "10.0.0.6", IPv4AddressInt: 0x0a000006, Status: 1})
+
+	if moved.Id != replaced.Id {
+		t.Errorf("moved device id = %v, want %v", moved.Id, replaced.Id)
+	}
+
+	if _, record := accessPoints.GetRecordByMacAddress("0a003eaabbcc"); record.Status != 1 {
+		t.Errorf("moved device status = %v, want 1", record.Status)
+	}
+
+	if _, record := accessPoints.GetRecordByMacAddress("0a003eaabbdd"); record.Status != 1 {
+		t.Errorf("replacement status after the move = %v, want 1", record.Status)
+	}
+}
+
+func TestUpsertRecordClaimsUnidentifiedDevice(t *testing.T) {
+	subscriberModules := migratedRepositories(t).SubscriberModules
+
+	_, unidentified := subscriberModules.UpsertRecord(device.SubscriberModule{NetworkId: 1,
+		MacAddress: device.UnknownMacAddress, IPv4Address: "10.0.1.5", Status: 1})
+	_, identified := subscriberModules.UpsertRecord(device.SubscriberModule{NetworkId: 1, MacAddress: "0a003eaabbee",
+		IPv4Address: "10.0.1.5", Status: 1})
+
+	if identified.Id != unidentified.Id {
+		t.Errorf("identified device id = %v, want the unidentified record %v", identified.Id, unidentified.Id)
+	}
+
+	if _, records := subscriberModules.GetRecords(); len(records) != 1 {
+		t.Errorf("records = %v, want 1", records)
+	}
+}
@@ -0,0 +1,307 @@
+package database
+
+import (
+	"as/camscan/internal/camscan/database/repository"
+	"as/camscan/internal/camscan/logging"
+	"as/camscan/internal/camscan/types"
+	"database/sql"
+	"embed"
+	"errors"
+	"fmt"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+//go:embed migrations/mysql/*.sql migrations/postgres/*.sql migrations/sqlite/*.sql
+var migrationFiles embed.FS
+
+const migrationPath = "migrations"
+
+var ErrSchemaOutdated = errors.New("database schema is out of date; run 'camscan db migrate'")
+
+var createTablePattern = regexp.MustCompile(`(?i)CREATE\s+TABLE\s+(?:IF\s+NOT\s+EXISTS\s+)?(\w+)`)
+
+// execer runs the statements of a migration either directly on the database or within a transaction
+type execer interface {
+	Exec(query string, args ...interface{}) (sql.Result, error)
+}
+
+var schemaVersionQuery = `CREATE TABLE IF NOT EXISTS schema_version
+						  (
+							  version INT          NOT NULL,
+							  name    VARCHAR(255) NOT NULL,
+							  applied INT          NOT NULL,
+							  PRIMARY KEY (version)
+						  )`
+
+// GetMigrations loads the migrations embedded in the program for the given driver ordered by version. Migration files
+// are named like "0001_initial.sql" where the number is the schema version the migration upgrades to.
+func GetMigrations(driver string) (bool, []types.SchemaMigration) {
+	migrations := make([]types.SchemaMigration, 0)
+	driverPath := path.Join(migrationPath, driver)
+
+	entries, err := migrationFiles.ReadDir(driverPath)
+
+	if err != nil {
+		logging.Error("Failed to load embedded database migrations; driver: %s; error: %s;", driver, err.Error())
+		return false, migrations
+	}
+
+	for _, entry := range entries {
+		versionText, name, found := strings.Cut(strings.TrimSuffix(entry.Name(), ".sql"), "_")
+		version, err := strconv.Atoi(versionText)
+
+		if !found || err != nil {
+			logging.Error("Invalid database migration file name; name: %s;", entry.Name())
+			return false, migrations
+		}
+
+		migrations = append(migrations, types.SchemaMigration{
+			Version: version,
+			Name:    name,
+			File:    path.Join(driverPath, entry.Name()),
+		})
+	}
+
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+
+	return true, migrations
+}
+
+// GetLatestSchemaVersion returns the schema version the embedded migrations upgrade the database to.
+func GetLatestSchemaVersion(driver string) int {
+	_, migrations := GetMigrations(driver)
+
+	if len(migrations) == 0 {
+		return 0
+	}
+
+	return migrations[len(migrations)-1].Version
+}
+
+// GetSchemaVersion returns the schema version of the given database, which is zero for a database that isn't managed
+// by migrations yet. The database is only read, so the schema version table isn't created when missing.
+func GetSchemaVersion(db *sql.DB, driver string) (bool, int) {
+	version := 0
+	query := `SELECT COUNT(*) FROM information_schema.tables
+			  WHERE table_schema = DATABASE() AND table_name = 'schema_version'`
+
+	switch driver {
+	case repository.DriverPostgres:
+		query = `SELECT COUNT(*) FROM information_schema.tables
+				 WHERE table_schema = current_schema() AND table_name = 'schema_version'`
+	case repository.DriverSQLite:
+		query = `SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = 'schema_version'`
+	}
+
+	tables := 0
+
+	if err := db.QueryRow(query).Scan(&tables); err != nil {
+		logging.Error("Failed to look up schema version table; error: %s;", err.Error())
+		return false, version
+	}
+
+	if tables == 0 {
+		return true, version
+	}
+
+	err := db.QueryRow(`SELECT COALESCE(MAX(version), 0) FROM schema_version`).Scan(&version)
+
+	if err != nil {
+		logging.Error("Failed to retrieve schema version; error: %s;", err.Error())
+		return false, version
+	}
+
+	return true, version
+}
+
+// GetMigrationStatus returns every embedded migration, where the applied timestamp is zero for pending migrations.
+func GetMigrationStatus(db *sql.DB, driver string) (bool, []types.SchemaMigration) {
+	success, migrations := GetMigrations(driver)
+
+	if success != true {
+		return false, migrations
+	}
+
+	if _, err := db.Exec(schemaVersionQuery); err != nil {
+		logging.Error("Failed to create schema version table; error: %s;", err.Error())
+		return false, migrations
+	}
+
+	sqlResults, err := db.Query(`SELECT version, applied FROM schema_version`)
+
+	if err != nil {
+		logging.Error("Failed to retrieve applied migrations; error: %s;", err.Error())
+		return false, migrations
+	}
+
+	defer func(sqlResults *sql.Rows) {
+		_ = sqlResults.Close()
+	}(sqlResults)
+
+	applied := make(map[int]int)
+
+	for sqlResults.Next() {
+		var version, timestamp int
+		_ = sqlResults.Scan(&version, &timestamp)
+		applied[version] = timestamp
+	}
+
+	for i := range migrations {
+		migrations[i].Applied = applied[migrations[i].Version]
+	}
+
+	return true, migrations
+}
+
+// Migrate applies every pending migration in order, recording each one in the schema_version table.
+func Migrate(db *sql.DB, driver string) bool {
+	success, migrations := GetMigrationStatus(db, driver)
+
+	if success != true {
+		return false
+	}
+
+	for _, migration := range migrations {
+		if migration.Applied > 0 {
+			continue
+		}
+
+		logging.Info("Applying database migration; version: %v; name: %s;", migration.Version, migration.Name)
+
+		if err := applyMigration(db, driver, migration); err != nil {
+			logging.Error("Failed to apply database migration; version: %v; name: %s; error: %s;",
+				migration.Version, migration.Name, err.Error())
+			return false
+		}
+	}
+
+	return true
+}
+
+// applyMigration runs the statements of the given migration and records it in a single transaction, so a migration
+// that fails is rolled back entirely. MySQL commits every DDL statement implicitly, so its migrations aren't run in a
+// transaction and the statements of a failed one that did succeed have to be reverted by hand before it's retried.
+func applyMigration(db *sql.DB, driver string, migration types.SchemaMigration) error {
+	contents, err := migrationFiles.ReadFile(migration.File)
+
+	if err != nil {
+		return err
+	}
+
+	if driver == repository.DriverMySQL {
+		return execMigration(db, migration, string(contents))
+	}
+
+	tx, err := db.Begin()
+
+	if err != nil {
+		return err
+	}
+
+	if err = execMigration(tx, migration, string(contents)); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func execMigration(db execer, migration types.SchemaMigration, contents string) error {
+	// Statements are executed one at a time since the MySQL driver rejects multiple statements per query
+	for _, statement := range strings.Split(contents, ";\n") {
+		if strings.Trim(statement, " \r\n\t") == "" {
+			continue
+		}
+
+		if _, err := db.Exec(statement); err != nil {
+			return fmt.Errorf("%s; statement: %s", err.Error(), strings.Trim(statement, " \r\n\t"))
+		}
+	}
+
+	return recordMigration(db, migration)
+}
+
+func recordMigration(db execer, migration types.SchemaMigration) error {
+	_, err := db.Exec(`INSERT INTO schema_version(version, name, applied) VALUES (?, ?, ?)`,
+		migration.Version, migration.Name, time.Now().Unix())
+
+	return err
+}
+
+// Baseline adopts a database whose tables were created before it was managed by migrations, by recording the initial
+// migration as applied without running it. Every table that the initial migration creates has to exist already, and
+// no migration may have been recorded yet. Later migrations are then applied by Migrate as usual.
+func Baseline(db *sql.DB, driver string) bool {
+	success, migrations := GetMigrationStatus(db, driver)
+
+	if success != true {
+		return false
+	}
+
+	if len(migrations) == 0 {
+		logging.Error("No database migrations to record; driver: %s;", driver)
+		return false
+	}
+
+	for _, migration := range migrations {
+		if migration.Applied > 0 {
+			logging.Error("Database schema is already managed by migrations; version: %v; name: %s;",
+				migration.Version, migration.Name)
+			return false
+		}
+	}
+
+	initial := migrations[0]
+	contents, err := migrationFiles.ReadFile(initial.File)
+
+	if err != nil {
+		logging.Error("Failed to load initial database migration; file: %s; error: %s;", initial.File, err.Error())
+		return false
+	}
+
+	for _, match := range createTablePattern.FindAllStringSubmatch(string(contents), -1) {
+		sqlResults, err := db.Query(`SELECT * FROM ` + match[1] + ` WHERE 1 = 0`)
+
+		if err != nil {
+			logging.Error("Database table of the initial migration doesn't exist; table: %s; error: %s;",
+				match[1], err.Error())
+			return false
+		}
+
+		_ = sqlResults.Close()
+	}
+
+	if err = recordMigration(db, initial); err != nil {
+		logging.Error("Failed to record initial database migration; version: %v; name: %s; error: %s;",
+			initial.Version, initial.Name, err.Error())
+		return false
+	}
+
+	logging.Info("Recorded initial database migration as applied; version: %v; name: %s;", initial.Version,
+		initial.Name)
+
+	return true
+}
+
+// CheckSchema verifies that every embedded migration of the given driver has been applied to the given database,
+// without changing the database.
+func CheckSchema(db *sql.DB, driver string) error {
+	success, version := GetSchemaVersion(db, driver)
+
+	if success != true {
+		return errors.New("failed to determine the database schema version")
+	}
+
+	if latest := GetLatestSchemaVersion(driver); version < latest {
+		return fmt.Errorf("%w; version: %v; latest: %v", ErrSchemaOutdated, version, latest)
+	}
+
+	return nil
+}
@@ -0,0 +1,39 @@
+package database
+
+import (
+	"as/camscan/internal/camscan/database/repository"
+	"database/sql"
+	"errors"
+	"path/filepath"
+	"testing"
+)
+
+func TestCheckSchemaDoesNotChangeDatabase(t *testing.T) {
+	db, err := sql.Open("sqlite3", filepath.Join(t.TempDir(), "camscan.db"))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer func(db *sql.DB) {
+		_ = db.Close()
+	}(db)
+
+	if err = CheckSchema(db, repository.DriverSQLite); !errors.Is(err, ErrSchemaOutdated) {
+		t.Fatalf("error = %v, want %v", err, ErrSchemaOutdated)
+	}
+
+	tables := 0
+
+	if err = db.QueryRow(`SELECT COUNT(*) FROM sqlite_master`).Scan(&tables); err != nil || tables != 0 {
+		t.Fatalf("tables = %v, %v, want an empty database", tables, err)
+	}
+
+	if !Migrate(db, repository.DriverSQLite) {
+		t.Fatal("migration failed")
+	}
+
+	if err = CheckSchema(db, repository.DriverSQLite); err != nil {
+		t.Errorf("error = %v, want nil after migrating", err)
+	}
+}
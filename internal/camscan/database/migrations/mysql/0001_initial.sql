@@ -0,0 +1,103 @@
+CREATE TABLE network_subnet
+(
+    id                       INT UNSIGNED     NOT NULL AUTO_INCREMENT,
+    network_id               INT UNSIGNED     NOT NULL DEFAULT 0,
+    cidr                     VARCHAR(18)      NOT NULL,
+    ipv4_network_address     VARCHAR(15)      NOT NULL,
+    ipv4_network_address_int INT UNSIGNED     NOT NULL DEFAULT 0,
+    ipv4_network_mask        TINYINT UNSIGNED NOT NULL DEFAULT 32,
+    status                   TINYINT          NOT NULL DEFAULT 1,
+    PRIMARY KEY (id),
+    UNIQUE KEY uq_network_subnet_cidr (network_id, cidr)
+) ENGINE = InnoDB
+  DEFAULT CHARSET = utf8mb4;
+
+CREATE TABLE device_access_point
+(
+    id               INT UNSIGNED     NOT NULL AUTO_INCREMENT,
+    network_id       INT UNSIGNED     NOT NULL DEFAULT 0,
+    mac_address      CHAR(12)         NOT NULL DEFAULT '000000000000',
+    ipv4_address     VARCHAR(15)      NOT NULL,
+    ipv4_address_int INT UNSIGNED     NOT NULL DEFAULT 0,
+    snmp_credential  VARCHAR(64)      NOT NULL DEFAULT '',
+    snmp_status      TINYINT UNSIGNED NOT NULL DEFAULT 0,
+    status           TINYINT          NOT NULL DEFAULT 1,
+    PRIMARY KEY (id),
+    KEY ix_device_access_point_mac_address (mac_address),
+    KEY ix_device_access_point_ipv4_address (ipv4_address)
+) ENGINE = InnoDB
+  DEFAULT CHARSET = utf8mb4;
+
+CREATE TABLE device_subscriber_module
+(
+    id               INT UNSIGNED     NOT NULL AUTO_INCREMENT,
+    network_id       INT UNSIGNED     NOT NULL DEFAULT 0,
+    mac_address      CHAR(12)         NOT NULL DEFAULT '000000000000',
+    ipv4_address     VARCHAR(15)      NOT NULL,
+    ipv4_address_int INT UNSIGNED     NOT NULL DEFAULT 0,
+    snmp_credential  VARCHAR(64)      NOT NULL DEFAULT '',
+    snmp_status      TINYINT UNSIGNED NOT NULL DEFAULT 0,
+    status           TINYINT          NOT NULL DEFAULT 1,
+    PRIMARY KEY (id),
+    KEY ix_device_subscriber_module_mac_address (mac_address),
+    KEY ix_device_subscriber_module_ipv4_address (ipv4_address)
+) ENGINE = InnoDB
+  DEFAULT CHARSET = utf8mb4;
+
+CREATE TABLE device_address_history
+(
+    id                   INT UNSIGNED     NOT NULL AUTO_INCREMENT,
+    device_type          TINYINT UNSIGNED NOT NULL,
+    device_id            INT UNSIGNED     NOT NULL,
+    mac_address          CHAR(12)         NOT NULL,
+    old_ipv4_address     VARCHAR(15)      NOT NULL,
+    old_ipv4_address_int INT UNSIGNED     NOT NULL DEFAULT 0,
+    new_ipv4_address     VARCHAR(15)      NOT NULL,
+    new_ipv4_address_int INT UNSIGNED     NOT NULL DEFAULT 0,
+    changed              INT UNSIGNED     NOT NULL,
+    PRIMARY KEY (id),
+    KEY ix_device_address_history_device (device_type, device_id, changed)
+) ENGINE = InnoDB
+  DEFAULT CHARSET = utf8mb4;
+
+CREATE TABLE snmp_oid_map
+(
+    id          INT UNSIGNED     NOT NULL AUTO_INCREMENT,
+    device_type TINYINT UNSIGNED NOT NULL,
+    key_name    VARCHAR(64)      NOT NULL,
+    oid         VARCHAR(255)     NOT NULL,
+    kind        VARCHAR(16)      NOT NULL DEFAULT 'scalar',
+    `order`     INT              NOT NULL DEFAULT 0,
+    PRIMARY KEY (id),
+    UNIQUE KEY uq_snmp_oid_map_key_name (device_type, key_name)
+) ENGINE = InnoDB
+  DEFAULT CHARSET = utf8mb4;
+
+CREATE TABLE snmp_scan
+(
+    id       INT UNSIGNED     NOT NULL AUTO_INCREMENT,
+    started  INT UNSIGNED     NOT NULL,
+    finished INT UNSIGNED     NOT NULL DEFAULT 0,
+    status   TINYINT UNSIGNED NOT NULL DEFAULT 1,
+    PRIMARY KEY (id)
+) ENGINE = InnoDB
+  DEFAULT CHARSET = utf8mb4;
+
+CREATE TABLE snmp_value
+(
+    id              BIGINT UNSIGNED  NOT NULL AUTO_INCREMENT,
+    scan_id         INT UNSIGNED     NOT NULL,
+    device_type     TINYINT UNSIGNED NOT NULL,
+    device_id       INT UNSIGNED     NOT NULL,
+    oid_map_id      INT UNSIGNED     NOT NULL,
+    oid_index       VARCHAR(128)     NOT NULL DEFAULT '',
+    snmp_type       TINYINT UNSIGNED NOT NULL,
+    snmp_value_char VARCHAR(255)     NOT NULL DEFAULT '',
+    snmp_value_num  DOUBLE           NOT NULL DEFAULT 0,
+    snmp_value_text TEXT             NOT NULL,
+    captured        INT UNSIGNED     NOT NULL,
+    PRIMARY KEY (id),
+    KEY ix_snmp_value_device (device_type, device_id, oid_map_id, captured),
+    KEY ix_snmp_value_scan (scan_id)
+) ENGINE = InnoDB
+  DEFAULT CHARSET = utf8mb4;
@@ -0,0 +1,12 @@
+CREATE TABLE snmp_poll_group
+(
+    id            INT UNSIGNED NOT NULL AUTO_INCREMENT,
+    name          VARCHAR(64)  NOT NULL,
+    poll_interval INT UNSIGNED NOT NULL DEFAULT 0,
+    PRIMARY KEY (id),
+    UNIQUE KEY uq_snmp_poll_group_name (name)
+) ENGINE = InnoDB
+  DEFAULT CHARSET = utf8mb4;
+
+ALTER TABLE snmp_oid_map
+    ADD COLUMN poll_group VARCHAR(64) NOT NULL DEFAULT '' AFTER `order`;
@@ -0,0 +1,15 @@
+CREATE TABLE snmp_poll_failure
+(
+    id          BIGINT UNSIGNED  NOT NULL AUTO_INCREMENT,
+    scan_id     INT UNSIGNED     NOT NULL,
+    device_type TINYINT UNSIGNED NOT NULL,
+    device_id   INT UNSIGNED     NOT NULL,
+    reason      VARCHAR(16)      NOT NULL,
+    attempts    INT UNSIGNED     NOT NULL DEFAULT 1,
+    message     VARCHAR(255)     NOT NULL DEFAULT '',
+    recorded    INT UNSIGNED     NOT NULL,
+    PRIMARY KEY (id),
+    KEY ix_snmp_poll_failure_device (device_type, device_id, recorded),
+    KEY ix_snmp_poll_failure_scan (scan_id)
+) ENGINE = InnoDB
+  DEFAULT CHARSET = utf8mb4;
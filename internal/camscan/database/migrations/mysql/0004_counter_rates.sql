@@ -0,0 +1,16 @@
+CREATE TABLE snmp_rate
+(
+    id          BIGINT UNSIGNED  NOT NULL AUTO_INCREMENT,
+    scan_id     INT UNSIGNED     NOT NULL,
+    device_type TINYINT UNSIGNED NOT NULL,
+    device_id   INT UNSIGNED     NOT NULL,
+    oid_map_id  INT UNSIGNED     NOT NULL,
+    oid_index   VARCHAR(128)     NOT NULL DEFAULT '',
+    rate        DOUBLE           NOT NULL DEFAULT 0,
+    period      INT UNSIGNED     NOT NULL,
+    captured    INT UNSIGNED     NOT NULL,
+    PRIMARY KEY (id),
+    KEY ix_snmp_rate_device (device_type, device_id, oid_map_id, captured),
+    KEY ix_snmp_rate_scan (scan_id)
+) ENGINE = InnoDB
+  DEFAULT CHARSET = utf8mb4;
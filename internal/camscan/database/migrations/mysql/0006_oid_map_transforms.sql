@@ -0,0 +1,9 @@
+ALTER TABLE snmp_oid_map
+    ADD COLUMN scale        DOUBLE        NOT NULL DEFAULT 1 AFTER poll_group,
+    ADD COLUMN value_offset DOUBLE        NOT NULL DEFAULT 0 AFTER scale,
+    ADD COLUMN pattern      VARCHAR(255)  NOT NULL DEFAULT '' AFTER value_offset,
+    ADD COLUMN enum_labels  VARCHAR(1024) NOT NULL DEFAULT '' AFTER pattern,
+    ADD COLUMN unit         VARCHAR(16)   NOT NULL DEFAULT '' AFTER enum_labels;
+
+ALTER TABLE snmp_value
+    ADD COLUMN unit VARCHAR(16) NOT NULL DEFAULT '' AFTER snmp_value_text;
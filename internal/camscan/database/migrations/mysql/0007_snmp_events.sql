@@ -0,0 +1,33 @@
+CREATE TABLE snmp_event
+(
+    id             BIGINT UNSIGNED  NOT NULL AUTO_INCREMENT,
+    device_type    TINYINT UNSIGNED NOT NULL DEFAULT 0,
+    device_id      INT UNSIGNED     NOT NULL DEFAULT 0,
+    source_address VARCHAR(45)      NOT NULL,
+    version        VARCHAR(8)       NOT NULL,
+    pdu_type       TINYINT UNSIGNED NOT NULL,
+    trap_oid       VARCHAR(255)     NOT NULL DEFAULT '',
+    trap_name      VARCHAR(255)     NOT NULL DEFAULT '',
+    uptime         BIGINT UNSIGNED  NOT NULL DEFAULT 0,
+    received       INT UNSIGNED     NOT NULL,
+    PRIMARY KEY (id),
+    KEY ix_snmp_event_device (device_type, device_id, received),
+    KEY ix_snmp_event_received (received)
+) ENGINE = InnoDB
+  DEFAULT CHARSET = utf8mb4;
+
+CREATE TABLE snmp_event_value
+(
+    id              BIGINT UNSIGNED  NOT NULL AUTO_INCREMENT,
+    event_id        BIGINT UNSIGNED  NOT NULL,
+    value_oid       VARCHAR(255)     NOT NULL,
+    value_name      VARCHAR(255)     NOT NULL DEFAULT '',
+    snmp_type       TINYINT UNSIGNED NOT NULL,
+    snmp_value_char VARCHAR(255)     NOT NULL DEFAULT '',
+    snmp_value_num  DOUBLE           NOT NULL DEFAULT 0,
+    snmp_value_text TEXT             NOT NULL,
+    unit            VARCHAR(16)      NOT NULL DEFAULT '',
+    PRIMARY KEY (id),
+    KEY ix_snmp_event_value_event (event_id)
+) ENGINE = InnoDB
+  DEFAULT CHARSET = utf8mb4;
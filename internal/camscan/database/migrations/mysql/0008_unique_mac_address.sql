@@ -0,0 +1,23 @@
+UPDATE device_access_point d
+    INNER JOIN (SELECT mac_address, MIN(id) AS id
+                FROM device_access_point
+                WHERE mac_address <> '000000000000'
+                GROUP BY mac_address
+                HAVING COUNT(*) > 1) kept ON kept.mac_address = d.mac_address AND kept.id <> d.id
+SET d.mac_address = '000000000000';
+
+UPDATE device_subscriber_module d
+    INNER JOIN (SELECT mac_address, MIN(id) AS id
+                FROM device_subscriber_module
+                WHERE mac_address <> '000000000000'
+                GROUP BY mac_address
+                HAVING COUNT(*) > 1) kept ON kept.mac_address = d.mac_address AND kept.id <> d.id
+SET d.mac_address = '000000000000';
+
+ALTER TABLE device_access_point
+    ADD COLUMN known_mac_address CHAR(12) GENERATED ALWAYS AS (NULLIF(mac_address, '000000000000')) STORED,
+    ADD UNIQUE KEY uq_device_access_point_mac_address (known_mac_address);
+
+ALTER TABLE device_subscriber_module
+    ADD COLUMN known_mac_address CHAR(12) GENERATED ALWAYS AS (NULLIF(mac_address, '000000000000')) STORED,
+    ADD UNIQUE KEY uq_device_subscriber_module_mac_address (known_mac_address);
@@ -0,0 +1,8 @@
+CREATE TABLE device_registration
+(
+    subscriber_module_id INT UNSIGNED NOT NULL,
+    access_point_id      INT UNSIGNED NOT NULL,
+    updated              INT UNSIGNED NOT NULL,
+    PRIMARY KEY (subscriber_module_id)
+) ENGINE = InnoDB
+  DEFAULT CHARSET = utf8mb4;
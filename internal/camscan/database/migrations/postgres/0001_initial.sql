@@ -0,0 +1,98 @@
+CREATE TABLE network_subnet
+(
+    id                       SERIAL      NOT NULL PRIMARY KEY,
+    network_id               INTEGER     NOT NULL DEFAULT 0,
+    cidr                     VARCHAR(18) NOT NULL,
+    ipv4_network_address     VARCHAR(15) NOT NULL,
+    ipv4_network_address_int BIGINT      NOT NULL DEFAULT 0,
+    ipv4_network_mask        SMALLINT    NOT NULL DEFAULT 32,
+    status                   SMALLINT    NOT NULL DEFAULT 1,
+    CONSTRAINT uq_network_subnet_cidr UNIQUE (network_id, cidr)
+);
+
+CREATE TABLE device_access_point
+(
+    id               SERIAL      NOT NULL PRIMARY KEY,
+    network_id       INTEGER     NOT NULL DEFAULT 0,
+    mac_address      CHAR(12)    NOT NULL DEFAULT '000000000000',
+    ipv4_address     VARCHAR(15) NOT NULL,
+    ipv4_address_int BIGINT      NOT NULL DEFAULT 0,
+    snmp_credential  VARCHAR(64) NOT NULL DEFAULT '',
+    snmp_status      SMALLINT    NOT NULL DEFAULT 0,
+    status           SMALLINT    NOT NULL DEFAULT 1
+);
+
+CREATE UNIQUE INDEX uq_device_access_point_mac_address ON device_access_point (mac_address)
+    WHERE mac_address <> '000000000000';
+
+CREATE INDEX ix_device_access_point_ipv4_address ON device_access_point (ipv4_address);
+
+CREATE TABLE device_subscriber_module
+(
+    id               SERIAL      NOT NULL PRIMARY KEY,
+    network_id       INTEGER     NOT NULL DEFAULT 0,
+    mac_address      CHAR(12)    NOT NULL DEFAULT '000000000000',
+    ipv4_address     VARCHAR(15) NOT NULL,
+    ipv4_address_int BIGINT      NOT NULL DEFAULT 0,
+    snmp_credential  VARCHAR(64) NOT NULL DEFAULT '',
+    snmp_status      SMALLINT    NOT NULL DEFAULT 0,
+    status           SMALLINT    NOT NULL DEFAULT 1
+);
+
+CREATE UNIQUE INDEX uq_device_subscriber_module_mac_address ON device_subscriber_module (mac_address)
+    WHERE mac_address <> '000000000000';
+
+CREATE INDEX ix_device_subscriber_module_ipv4_address ON device_subscriber_module (ipv4_address);
+
+CREATE TABLE device_address_history
+(
+    id                   SERIAL      NOT NULL PRIMARY KEY,
+    device_type          SMALLINT    NOT NULL,
+    device_id            INTEGER     NOT NULL,
+    mac_address          CHAR(12)    NOT NULL,
+    old_ipv4_address     VARCHAR(15) NOT NULL,
+    old_ipv4_address_int BIGINT      NOT NULL DEFAULT 0,
+    new_ipv4_address     VARCHAR(15) NOT NULL,
+    new_ipv4_address_int BIGINT      NOT NULL DEFAULT 0,
+    changed              BIGINT      NOT NULL
+);
+
+CREATE INDEX ix_device_address_history_device ON device_address_history (device_type, device_id, changed);
+
+CREATE TABLE snmp_oid_map
+(
+    id          SERIAL       NOT NULL PRIMARY KEY,
+    device_type SMALLINT     NOT NULL,
+    key_name    VARCHAR(64)  NOT NULL,
+    oid         VARCHAR(255) NOT NULL,
+    kind        VARCHAR(16)  NOT NULL DEFAULT 'scalar',
+    "order"     INTEGER      NOT NULL DEFAULT 0,
+    CONSTRAINT uq_snmp_oid_map_key_name UNIQUE (device_type, key_name)
+);
+
+CREATE TABLE snmp_scan
+(
+    id       SERIAL   NOT NULL PRIMARY KEY,
+    started  BIGINT   NOT NULL,
+    finished BIGINT   NOT NULL DEFAULT 0,
+    status   SMALLINT NOT NULL DEFAULT 1
+);
+
+CREATE TABLE snmp_value
+(
+    id              BIGSERIAL        NOT NULL PRIMARY KEY,
+    scan_id         INTEGER          NOT NULL,
+    device_type     SMALLINT         NOT NULL,
+    device_id       INTEGER          NOT NULL,
+    oid_map_id      INTEGER          NOT NULL,
+    oid_index       VARCHAR(128)     NOT NULL DEFAULT '',
+    snmp_type       SMALLINT         NOT NULL,
+    snmp_value_char VARCHAR(255)     NOT NULL DEFAULT '',
+    snmp_value_num  DOUBLE PRECISION NOT NULL DEFAULT 0,
+    snmp_value_text TEXT             NOT NULL DEFAULT '',
+    captured        BIGINT           NOT NULL
+);
+
+CREATE INDEX ix_snmp_value_device ON snmp_value (device_type, device_id, oid_map_id, captured);
+
+CREATE INDEX ix_snmp_value_scan ON snmp_value (scan_id);
@@ -0,0 +1,10 @@
+CREATE TABLE snmp_poll_group
+(
+    id            SERIAL      NOT NULL PRIMARY KEY,
+    name          VARCHAR(64) NOT NULL,
+    poll_interval INTEGER     NOT NULL DEFAULT 0,
+    CONSTRAINT uq_snmp_poll_group_name UNIQUE (name)
+);
+
+ALTER TABLE snmp_oid_map
+    ADD COLUMN poll_group VARCHAR(64) NOT NULL DEFAULT '';
@@ -0,0 +1,15 @@
+CREATE TABLE snmp_poll_failure
+(
+    id          BIGSERIAL    NOT NULL PRIMARY KEY,
+    scan_id     INTEGER      NOT NULL,
+    device_type SMALLINT     NOT NULL,
+    device_id   INTEGER      NOT NULL,
+    reason      VARCHAR(16)  NOT NULL,
+    attempts    INTEGER      NOT NULL DEFAULT 1,
+    message     VARCHAR(255) NOT NULL DEFAULT '',
+    recorded    BIGINT       NOT NULL
+);
+
+CREATE INDEX ix_snmp_poll_failure_device ON snmp_poll_failure (device_type, device_id, recorded);
+
+CREATE INDEX ix_snmp_poll_failure_scan ON snmp_poll_failure (scan_id);
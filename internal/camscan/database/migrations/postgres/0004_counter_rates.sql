@@ -0,0 +1,16 @@
+CREATE TABLE snmp_rate
+(
+    id          BIGSERIAL        NOT NULL PRIMARY KEY,
+    scan_id     INTEGER          NOT NULL,
+    device_type SMALLINT         NOT NULL,
+    device_id   INTEGER          NOT NULL,
+    oid_map_id  INTEGER          NOT NULL,
+    oid_index   VARCHAR(128)     NOT NULL DEFAULT '',
+    rate        DOUBLE PRECISION NOT NULL DEFAULT 0,
+    period      INTEGER          NOT NULL,
+    captured    BIGINT           NOT NULL
+);
+
+CREATE INDEX ix_snmp_rate_device ON snmp_rate (device_type, device_id, oid_map_id, captured);
+
+CREATE INDEX ix_snmp_rate_scan ON snmp_rate (scan_id);
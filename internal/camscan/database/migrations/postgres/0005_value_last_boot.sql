@@ -0,0 +1,4 @@
+ALTER TABLE snmp_value
+    ADD COLUMN last_boot BIGINT NOT NULL DEFAULT 0;
+
+CREATE INDEX ix_snmp_value_last_boot ON snmp_value (device_type, last_boot);
@@ -0,0 +1,9 @@
+ALTER TABLE snmp_oid_map
+    ADD COLUMN scale        DOUBLE PRECISION NOT NULL DEFAULT 1,
+    ADD COLUMN value_offset DOUBLE PRECISION NOT NULL DEFAULT 0,
+    ADD COLUMN pattern      VARCHAR(255)     NOT NULL DEFAULT '',
+    ADD COLUMN enum_labels  VARCHAR(1024)    NOT NULL DEFAULT '',
+    ADD COLUMN unit         VARCHAR(16)      NOT NULL DEFAULT '';
+
+ALTER TABLE snmp_value
+    ADD COLUMN unit VARCHAR(16) NOT NULL DEFAULT '';
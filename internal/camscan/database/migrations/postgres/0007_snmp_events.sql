@@ -0,0 +1,32 @@
+CREATE TABLE snmp_event
+(
+    id             BIGSERIAL    NOT NULL PRIMARY KEY,
+    device_type    SMALLINT     NOT NULL DEFAULT 0,
+    device_id      INTEGER      NOT NULL DEFAULT 0,
+    source_address VARCHAR(45)  NOT NULL,
+    version        VARCHAR(8)   NOT NULL,
+    pdu_type       SMALLINT     NOT NULL,
+    trap_oid       VARCHAR(255) NOT NULL DEFAULT '',
+    trap_name      VARCHAR(255) NOT NULL DEFAULT '',
+    uptime         BIGINT       NOT NULL DEFAULT 0,
+    received       BIGINT       NOT NULL
+);
+
+CREATE INDEX ix_snmp_event_device ON snmp_event (device_type, device_id, received);
+
+CREATE INDEX ix_snmp_event_received ON snmp_event (received);
+
+CREATE TABLE snmp_event_value
+(
+    id              BIGSERIAL        NOT NULL PRIMARY KEY,
+    event_id        BIGINT           NOT NULL,
+    value_oid       VARCHAR(255)     NOT NULL,
+    value_name      VARCHAR(255)     NOT NULL DEFAULT '',
+    snmp_type       SMALLINT         NOT NULL,
+    snmp_value_char VARCHAR(255)     NOT NULL DEFAULT '',
+    snmp_value_num  DOUBLE PRECISION NOT NULL DEFAULT 0,
+    snmp_value_text TEXT             NOT NULL DEFAULT '',
+    unit            VARCHAR(16)      NOT NULL DEFAULT ''
+);
+
+CREATE INDEX ix_snmp_event_value_event ON snmp_event_value (event_id);
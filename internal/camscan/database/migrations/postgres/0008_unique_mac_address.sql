@@ -0,0 +1,5 @@
+CREATE UNIQUE INDEX IF NOT EXISTS uq_device_access_point_mac_address ON device_access_point (mac_address)
+    WHERE mac_address <> '000000000000';
+
+CREATE UNIQUE INDEX IF NOT EXISTS uq_device_subscriber_module_mac_address ON device_subscriber_module (mac_address)
+    WHERE mac_address <> '000000000000';
@@ -0,0 +1,6 @@
+CREATE TABLE device_registration
+(
+    subscriber_module_id INTEGER NOT NULL PRIMARY KEY,
+    access_point_id      INTEGER NOT NULL,
+    updated              BIGINT  NOT NULL
+);
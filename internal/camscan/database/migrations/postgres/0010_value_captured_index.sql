@@ -0,0 +1 @@
+CREATE INDEX ix_snmp_value_captured ON snmp_value (device_type, captured);
@@ -0,0 +1,11 @@
+CREATE TABLE snmp_poll_group
+(
+    id            INTEGER     NOT NULL PRIMARY KEY AUTOINCREMENT,
+    name          VARCHAR(64) NOT NULL,
+    poll_interval INTEGER     NOT NULL DEFAULT 0
+);
+
+CREATE UNIQUE INDEX uq_snmp_poll_group_name ON snmp_poll_group (name);
+
+ALTER TABLE snmp_oid_map
+    ADD COLUMN poll_group VARCHAR(64) NOT NULL DEFAULT '';
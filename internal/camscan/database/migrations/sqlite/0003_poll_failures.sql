@@ -0,0 +1,15 @@
+CREATE TABLE snmp_poll_failure
+(
+    id          INTEGER      NOT NULL PRIMARY KEY AUTOINCREMENT,
+    scan_id     INTEGER      NOT NULL,
+    device_type INTEGER      NOT NULL,
+    device_id   INTEGER      NOT NULL,
+    reason      VARCHAR(16)  NOT NULL,
+    attempts    INTEGER      NOT NULL DEFAULT 1,
+    message     VARCHAR(255) NOT NULL DEFAULT '',
+    recorded    INTEGER      NOT NULL
+);
+
+CREATE INDEX ix_snmp_poll_failure_device ON snmp_poll_failure (device_type, device_id, recorded);
+
+CREATE INDEX ix_snmp_poll_failure_scan ON snmp_poll_failure (scan_id);
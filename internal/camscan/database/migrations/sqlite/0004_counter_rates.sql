@@ -0,0 +1,16 @@
+CREATE TABLE snmp_rate
+(
+    id          INTEGER      NOT NULL PRIMARY KEY AUTOINCREMENT,
+    scan_id     INTEGER      NOT NULL,
+    device_type INTEGER      NOT NULL,
+    device_id   INTEGER      NOT NULL,
+    oid_map_id  INTEGER      NOT NULL,
+    oid_index   VARCHAR(128) NOT NULL DEFAULT '',
+    rate        REAL         NOT NULL DEFAULT 0,
+    period      INTEGER      NOT NULL,
+    captured    INTEGER      NOT NULL
+);
+
+CREATE INDEX ix_snmp_rate_device ON snmp_rate (device_type, device_id, oid_map_id, captured);
+
+CREATE INDEX ix_snmp_rate_scan ON snmp_rate (scan_id);
@@ -0,0 +1,17 @@
+ALTER TABLE snmp_oid_map
+    ADD COLUMN scale REAL NOT NULL DEFAULT 1;
+
+ALTER TABLE snmp_oid_map
+    ADD COLUMN value_offset REAL NOT NULL DEFAULT 0;
+
+ALTER TABLE snmp_oid_map
+    ADD COLUMN pattern VARCHAR(255) NOT NULL DEFAULT '';
+
+ALTER TABLE snmp_oid_map
+    ADD COLUMN enum_labels VARCHAR(1024) NOT NULL DEFAULT '';
+
+ALTER TABLE snmp_oid_map
+    ADD COLUMN unit VARCHAR(16) NOT NULL DEFAULT '';
+
+ALTER TABLE snmp_value
+    ADD COLUMN unit VARCHAR(16) NOT NULL DEFAULT '';
@@ -0,0 +1,15 @@
+UPDATE device_access_point
+SET mac_address = '000000000000'
+WHERE mac_address <> '000000000000'
+  AND id NOT IN (SELECT MIN(id) FROM device_access_point WHERE mac_address <> '000000000000' GROUP BY mac_address);
+
+UPDATE device_subscriber_module
+SET mac_address = '000000000000'
+WHERE mac_address <> '000000000000'
+  AND id NOT IN (SELECT MIN(id) FROM device_subscriber_module WHERE mac_address <> '000000000000' GROUP BY mac_address);
+
+CREATE UNIQUE INDEX uq_device_access_point_mac_address ON device_access_point (mac_address)
+    WHERE mac_address <> '000000000000';
+
+CREATE UNIQUE INDEX uq_device_subscriber_module_mac_address ON device_subscriber_module (mac_address)
+    WHERE mac_address <> '000000000000';
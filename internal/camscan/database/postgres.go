@@ -0,0 +1,105 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"github.com/lib/pq"
+	"strconv"
+	"strings"
+)
+
+// The queries of the database packages are written with MySQL style "?" placeholders and backtick quoted
+// identifiers. PostgreSQL connections are opened through this driver, which rewrites both before handing the query to
+// the PostgreSQL driver.
+const postgresDriverName = "camscan-postgres"
+
+func init() {
+	sql.Register(postgresDriverName, postgresDriver{})
+}
+
+type postgresDriver struct{}
+
+func (d postgresDriver) Open(name string) (driver.Conn, error) {
+	conn, err := pq.Open(name)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return postgresConn{Conn: conn}, nil
+}
+
+// postgresConn rewrites every query before the PostgreSQL driver sees it, whether the query is prepared or queried and
+// executed directly. Ways of running a query that the PostgreSQL driver doesn't implement fall back to Prepare, so
+// that no query reaches it without being rewritten.
+type postgresConn struct {
+	driver.Conn
+}
+
+func (c postgresConn) Prepare(query string) (driver.Stmt, error) {
+	return c.Conn.Prepare(rebindQuery(query))
+}
+
+func (c postgresConn) PrepareContext(ctx context.Context, query string) (driver.Stmt, error) {
+	if preparer, ok := c.Conn.(driver.ConnPrepareContext); ok {
+		return preparer.PrepareContext(ctx, rebindQuery(query))
+	}
+
+	return c.Prepare(query)
+}
+
+func (c postgresConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	if queryer, ok := c.Conn.(driver.QueryerContext); ok {
+		return queryer.QueryContext(ctx, rebindQuery(query), args)
+	}
+
+	return nil, driver.ErrSkip
+}
+
+func (c postgresConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	if execer, ok := c.Conn.(driver.ExecerContext); ok {
+		return execer.ExecContext(ctx, rebindQuery(query), args)
+	}
+
+	return nil, driver.ErrSkip
+}
+
+func (c postgresConn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	if beginner, ok := c.Conn.(driver.ConnBeginTx); ok {
+		return beginner.BeginTx(ctx, opts)
+	}
+
+	return c.Conn.Begin()
+}
+
+// rebindQuery replaces "?" placeholders with numbered "$n" placeholders and backtick quoted identifiers with double
+// quoted ones, leaving string literals and double quoted identifiers untouched.
+func rebindQuery(query string) string {
+	var builder strings.Builder
+	var quote rune = 0
+	placeholder := 0
+
+	builder.Grow(len(query) + 16)
+
+	for _, char := range query {
+		switch {
+		case quote != 0:
+			if char == quote {
+				quote = 0
+			}
+		case char == '\'' || char == '"':
+			quote = char
+		case char == '?':
+			placeholder++
+			builder.WriteString("$" + strconv.Itoa(placeholder))
+			continue
+		case char == '`':
+			char = '"'
+		}
+
+		builder.WriteRune(char)
+	}
+
+	return builder.String()
+}
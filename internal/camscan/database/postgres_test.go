@@ -0,0 +1,138 @@
+package database
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"testing"
+)
+
+// fakeConn records the queries that reach it, like the PostgreSQL driver's connection would receive them
+type fakeConn struct {
+	queries []string
+}
+
+func (c *fakeConn) Prepare(query string) (driver.Stmt, error) {
+	c.queries = append(c.queries, query)
+	return nil, errors.New("not implemented")
+}
+
+func (c *fakeConn) Close() error {
+	return nil
+}
+
+func (c *fakeConn) Begin() (driver.Tx, error) {
+	return nil, errors.New("not implemented")
+}
+
+// fakeQueryerConn also queries and executes directly, like the PostgreSQL driver's connection does
+type fakeQueryerConn struct {
+	fakeConn
+}
+
+func (c *fakeQueryerConn) QueryContext(_ context.Context, query string, _ []driver.NamedValue) (driver.Rows, error) {
+	c.queries = append(c.queries, query)
+	return nil, nil
+}
+
+func (c *fakeQueryerConn) ExecContext(_ context.Context, query string, _ []driver.NamedValue) (driver.Result, error) {
+	c.queries = append(c.queries, query)
+	return nil, nil
+}
+
+func TestRebindQuery(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+		want  string
+	}{
+		{
+			name:  "placeholders",
+			query: `SELECT id FROM device_access_point WHERE mac_address = ? AND network_id = ?`,
+			want:  `SELECT id FROM device_access_point WHERE mac_address = $1 AND network_id = $2`,
+		},
+		{
+			name:  "no placeholders",
+			query: `SELECT COALESCE(MAX(version), 0) FROM schema_version`,
+			want:  `SELECT COALESCE(MAX(version), 0) FROM schema_version`,
+		},
+		{
+			name:  "question mark in a string literal",
+			query: `SELECT id FROM snmp_oid_map WHERE key_name = 'what?' AND device_type = ?`,
+			want:  `SELECT id FROM snmp_oid_map WHERE key_name = 'what?' AND device_type = $1`,
+		},
+		{
+			name:  "escaped quote in a string literal",
+			query: `UPDATE snmp_scan SET message = 'it''s done?' WHERE id = ?`,
+			want:  `UPDATE snmp_scan SET message = 'it''s done?' WHERE id = $1`,
+		},
+		{
+			name:  "question mark in a double quoted identifier",
+			query: `SELECT "odd?name" FROM t WHERE id = ?`,
+			want:  `SELECT "odd?name" FROM t WHERE id = $1`,
+		},
+		{
+			name:  "backtick quoted identifiers",
+			query: "SELECT `id`, `name` FROM `snmp_poll_group` WHERE `id` = ?",
+			want:  `SELECT "id", "name" FROM "snmp_poll_group" WHERE "id" = $1`,
+		},
+		{
+			name:  "reserved column names",
+			query: "INSERT INTO t(`user`, `order`, `group`) VALUES (?, ?, ?)",
+			want:  `INSERT INTO t("user", "order", "group") VALUES ($1, $2, $3)`,
+		},
+		{
+			name:  "backtick in a string literal",
+			query: "SELECT id FROM t WHERE note = 'a `b`' AND id = ?",
+			want:  "SELECT id FROM t WHERE note = 'a `b`' AND id = $1",
+		},
+		{
+			name:  "multi-row insert",
+			query: `INSERT INTO snmp_rate(scan_id, rate) VALUES (?, ?), (?, ?), (?, ?), (?, ?), (?, ?)`,
+			want:  `INSERT INTO snmp_rate(scan_id, rate) VALUES ($1, $2), ($3, $4), ($5, $6), ($7, $8), ($9, $10)`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := rebindQuery(test.query); got != test.want {
+				t.Errorf("rebindQuery(%q) = %q, want %q", test.query, got, test.want)
+			}
+		})
+	}
+}
+
+func TestPostgresConnRebindsEveryQuery(t *testing.T) {
+	query := "SELECT `id` FROM t WHERE a = ? AND b = ?"
+	want := `SELECT "id" FROM t WHERE a = $1 AND b = $2`
+	ctx := context.Background()
+
+	queryer := &fakeQueryerConn{}
+	conn := postgresConn{Conn: queryer}
+
+	_, _ = conn.Prepare(query)
+	_, _ = conn.PrepareContext(ctx, query)
+	_, _ = conn.QueryContext(ctx, query, nil)
+	_, _ = conn.ExecContext(ctx, query, nil)
+
+	if len(queryer.queries) != 4 {
+		t.Fatalf("queries = %v, want 4", queryer.queries)
+	}
+
+	for _, got := range queryer.queries {
+		if got != want {
+			t.Errorf("query = %q, want %q", got, want)
+		}
+	}
+
+	// Without direct queries, database/sql has to fall back to Prepare, which rewrites the query
+	conn = postgresConn{Conn: &fakeConn{}}
+
+	if _, err := conn.QueryContext(ctx, query, nil); !errors.Is(err, driver.ErrSkip) {
+		t.Errorf("QueryContext error = %v, want %v", err, driver.ErrSkip)
+	}
+
+	if _, err := conn.ExecContext(ctx, query, nil); !errors.Is(err, driver.ErrSkip) {
+		t.Errorf("ExecContext error = %v, want %v", err, driver.ErrSkip)
+	}
+}
@@ -0,0 +1,133 @@
+package repository
+
+import (
+	dbRegistration "as/camscan/internal/camscan/database/device/registration"
+	dbSubnet "as/camscan/internal/camscan/database/network/subnet"
+	dbGroup "as/camscan/internal/camscan/database/snmp/group"
+	dbOm "as/camscan/internal/camscan/database/snmp/om"
+	"as/camscan/internal/camscan/logging"
+	"as/camscan/internal/camscan/types/device"
+	"as/camscan/internal/camscan/types/network"
+	"as/camscan/internal/camscan/types/snmp"
+	"database/sql"
+)
+
+// SQLite and PostgreSQL share the ON CONFLICT upsert syntax, so the subnet, registration and OID map repositories of
+// both drivers use the same statements
+
+type conflictSubnetRepository struct {
+	db *sql.DB
+}
+
+func (r conflictSubnetRepository) GetRecords() (bool, []network.Subnet) {
+	return dbSubnet.GetRecords(r.db)
+}
+
+func (r conflictSubnetRepository) UpsertRecord(record network.Subnet) (bool, network.Subnet) {
+	sqlQuery := `INSERT INTO network_subnet(network_id, cidr, ipv4_network_address, ipv4_network_address_int,
+                           ipv4_network_mask, status)
+			     VALUES (?, ?, ?, ?, ?, ?)
+				 ON CONFLICT (network_id, cidr) DO UPDATE
+				     SET ipv4_network_address=excluded.ipv4_network_address,
+				         ipv4_network_address_int=excluded.ipv4_network_address_int,
+				         ipv4_network_mask=excluded.ipv4_network_mask, status=excluded.status`
+
+	_, sqlError := r.db.Exec(sqlQuery,
+		record.NetworkId,
+		record.Cidr,
+		record.IPv4NetworkAddress,
+		record.IPv4NetworkAddressInt,
+		record.IPv4NetworkMask,
+		record.Status,
+	)
+
+	if sqlError != nil {
+		logging.Error("Failed to create network subnet record; "+
+			"id: %v; nid: %v; cidr: %s; ipv4: %s; ipv4int: %v; mask: %v; status: %v; error: %s;",
+			record.Id, record.NetworkId, record.Cidr, record.IPv4NetworkAddress, record.IPv4NetworkAddressInt,
+			record.IPv4NetworkMask, record.Status, sqlError.Error())
+		return false, record
+	}
+
+	return true, record
+}
+
+type conflictRegistrationRepository struct {
+	db *sql.DB
+}
+
+func (r conflictRegistrationRepository) GetRecords() (bool, []device.Registration) {
+	return dbRegistration.GetRecords(r.db)
+}
+
+func (r conflictRegistrationRepository) UpsertRecord(record device.Registration) (bool, device.Registration) {
+	sqlQuery := `INSERT INTO device_registration(subscriber_module_id, access_point_id, updated)
+			     VALUES (?, ?, ?)
+				 ON CONFLICT (subscriber_module_id) DO UPDATE
+				     SET access_point_id=excluded.access_point_id, updated=excluded.updated`
+
+	_, sqlError := r.db.Exec(sqlQuery, record.SubscriberModuleId, record.AccessPointId, record.Updated)
+
+	if sqlError != nil {
+		logging.Error("Failed to create device registration record; sm: %v; ap: %v; error: %s;",
+			record.SubscriberModuleId, record.AccessPointId, sqlError.Error())
+		return false, record
+	}
+
+	return true, record
+}
+
+type conflictOidMapRepository struct {
+	db *sql.DB
+}
+
+func (r conflictOidMapRepository) GetRecords(deviceType int) (bool, []snmp.OidMap) {
+	return dbOm.GetRecords(r.db, deviceType)
+}
+
+func (r conflictOidMapRepository) UpsertRecord(record snmp.OidMap) (bool, snmp.OidMap) {
+	sqlQuery := "INSERT INTO snmp_oid_map(device_type, key_name, oid, kind, `order`, poll_group, scale, value_offset, " +
+		"pattern, enum_labels, unit) " +
+		"VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?) " +
+		"ON CONFLICT (device_type, key_name) DO UPDATE " +
+		"SET oid=excluded.oid, kind=excluded.kind, `order`=excluded.`order`, poll_group=excluded.poll_group, " +
+		"scale=excluded.scale, value_offset=excluded.value_offset, pattern=excluded.pattern, " +
+		"enum_labels=excluded.enum_labels, unit=excluded.unit"
+
+	_, sqlError := r.db.Exec(sqlQuery, record.DeviceType, record.KeyName, record.Oid, record.Kind, record.Order,
+		record.PollGroup, record.Transform.Factor(), record.Transform.Offset, record.Transform.Pattern,
+		record.Transform.Enum, record.Transform.Unit)
+
+	if sqlError != nil {
+		logging.Error("Failed to create SNMP OID map record; "+
+			"type: %v; key: %s; oid: %s; kind: %s; order: %v; error: %s;",
+			record.DeviceType, record.KeyName, record.Oid, record.Kind, record.Order, sqlError.Error())
+		return false, record
+	}
+
+	return true, record
+}
+
+type conflictPollGroupRepository struct {
+	db *sql.DB
+}
+
+func (r conflictPollGroupRepository) GetRecords() (bool, []snmp.PollGroup) {
+	return dbGroup.GetRecords(r.db)
+}
+
+func (r conflictPollGroupRepository) UpsertRecord(record snmp.PollGroup) (bool, snmp.PollGroup) {
+	sqlQuery := `INSERT INTO snmp_poll_group(name, poll_interval)
+			     VALUES (?, ?)
+				 ON CONFLICT (name) DO UPDATE SET poll_interval=excluded.poll_interval`
+
+	_, sqlError := r.db.Exec(sqlQuery, record.Name, record.Interval)
+
+	if sqlError != nil {
+		logging.Error("Failed to create SNMP poll group record; name: %s; interval: %v; error: %s;",
+			record.Name, record.Interval, sqlError.Error())
+		return false, record
+	}
+
+	return true, record
+}
@@ -0,0 +1,60 @@
+package repository
+
+import (
+	dbRegistration "as/camscan/internal/camscan/database/device/registration"
+	dbSubnet "as/camscan/internal/camscan/database/network/subnet"
+	dbGroup "as/camscan/internal/camscan/database/snmp/group"
+	dbOm "as/camscan/internal/camscan/database/snmp/om"
+	"as/camscan/internal/camscan/types/device"
+	"as/camscan/internal/camscan/types/network"
+	"as/camscan/internal/camscan/types/snmp"
+	"database/sql"
+)
+
+type mysqlSubnetRepository struct {
+	db *sql.DB
+}
+
+func (r mysqlSubnetRepository) GetRecords() (bool, []network.Subnet) {
+	return dbSubnet.GetRecords(r.db)
+}
+
+func (r mysqlSubnetRepository) UpsertRecord(record network.Subnet) (bool, network.Subnet) {
+	return dbSubnet.UpsertRecord(r.db, record)
+}
+
+type mysqlRegistrationRepository struct {
+	db *sql.DB
+}
+
+func (r mysqlRegistrationRepository) GetRecords() (bool, []device.Registration) {
+	return dbRegistration.GetRecords(r.db)
+}
+
+func (r mysqlRegistrationRepository) UpsertRecord(record device.Registration) (bool, device.Registration) {
+	return dbRegistration.UpsertRecord(r.db, record)
+}
+
+type mysqlOidMapRepository struct {
+	db *sql.DB
+}
+
+func (r mysqlOidMapRepository) GetRecords(deviceType int) (bool, []snmp.OidMap) {
+	return dbOm.GetRecords(r.db, deviceType)
+}
+
+func (r mysqlOidMapRepository) UpsertRecord(record snmp.OidMap) (bool, snmp.OidMap) {
+	return dbOm.UpsertRecord(r.db, record)
+}
+
+type mysqlPollGroupRepository struct {
+	db *sql.DB
+}
+
+func (r mysqlPollGroupRepository) GetRecords() (bool, []snmp.PollGroup) {
+	return dbGroup.GetRecords(r.db)
+}
+
+func (r mysqlPollGroupRepository) UpsertRecord(record snmp.PollGroup) (bool, snmp.PollGroup) {
+	return dbGroup.UpsertRecord(r.db, record)
+}
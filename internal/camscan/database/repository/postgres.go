@@ -0,0 +1,231 @@
+package repository
+
+import (
+	dbDevice "as/camscan/internal/camscan/database/device"
+	"as/camscan/internal/camscan/database/device/history"
+	dbEvent "as/camscan/internal/camscan/database/snmp/event"
+	"as/camscan/internal/camscan/logging"
+	"as/camscan/internal/camscan/types/device"
+	"as/camscan/internal/camscan/types/snmp"
+	"database/sql"
+	"time"
+)
+
+type postgresAccessPointRepository struct {
+	accessPointRepository
+}
+
+func (r postgresAccessPointRepository) UpsertRecord(record device.AccessPoint) (bool, device.AccessPoint) {
+	return upsertPostgresDevice(r.db, "device_access_point", snmp.DeviceTypeAccessPoint, record)
+}
+
+func (r postgresAccessPointRepository) InsertRecord(record device.AccessPoint) (bool, device.AccessPoint) {
+	return insertPostgresDevice(r.db, "device_access_point", record)
+}
+
+type postgresSubscriberModuleRepository struct {
+	subscriberModuleRepository
+}
+
+func (r postgresSubscriberModuleRepository) UpsertRecord(
+	record device.SubscriberModule) (bool, device.SubscriberModule) {
+	success, stored := upsertPostgresDevice(r.db, "device_subscriber_module", snmp.DeviceTypeSubscriberModule,
+		device.AccessPoint(record))
+
+	return success, device.SubscriberModule(stored)
+}
+
+func (r postgresSubscriberModuleRepository) InsertRecord(
+	record device.SubscriberModule) (bool, device.SubscriberModule) {
+	success, stored := insertPostgresDevice(r.db, "device_subscriber_module", device.AccessPoint(record))
+
+	return success, device.SubscriberModule(stored)
+}
+
+// upsertPostgresDevice stores the given device using its MAC address as the device identity, like the MySQL upsert
+// does, but with a single ON CONFLICT statement against the partial unique index of known MAC addresses. Access
+// points and subscriber modules share the same columns, so subscriber modules are passed in as access points. Like
+// the shared upsert, other records at the address of the device are retired.
+func upsertPostgresDevice(db *sql.DB, table string, deviceType int,
+	record device.AccessPoint) (bool, device.AccessPoint) {
+	var previousIPv4Address string
+	var previousIPv4AddressInt uint32
+
+	if record.MacAddress == "" || record.MacAddress == device.UnknownMacAddress {
+		return upsertPostgresUnknownDevice(db, table, record)
+	}
+
+	// Claim a record at the same address that has yet to be identified by its MAC address
+	_, sqlError := db.Exec(`UPDATE `+table+`
+						 SET mac_address = ?
+						 WHERE id = (SELECT id FROM `+table+`
+									 WHERE ipv4_address = ? AND mac_address = '`+device.UnknownMacAddress+`'
+									 ORDER BY id
+									 LIMIT 1)
+						   AND NOT EXISTS (SELECT 1 FROM `+table+` WHERE mac_address = ?)`,
+		record.MacAddress, record.IPv4Address, record.MacAddress)
+
+	if sqlError != nil {
+		logging.Error("Failed to claim unidentified device record; table: %s; mac: %s; ipv4: %s; error: %s;",
+			table, record.MacAddress, record.IPv4Address, sqlError.Error())
+		return false, record
+	}
+
+	sqlQuery := `WITH previous AS (SELECT ipv4_address, ipv4_address_int FROM ` + table + ` WHERE mac_address = ?)
+				 INSERT INTO ` + table + `(` + dbDevice.Columns + `)
+				 VALUES (?, ?, ?, ?, ?, ?, ?)
+				 ON CONFLICT (mac_address) WHERE mac_address <> '` + device.UnknownMacAddress + `' DO UPDATE
+				     SET network_id=excluded.network_id, ipv4_address=excluded.ipv4_address,
+				         ipv4_address_int=excluded.ipv4_address_int, snmp_credential=excluded.snmp_credential,
+				         snmp_status=excluded.snmp_status, status=excluded.status
+				 RETURNING id, COALESCE((SELECT ipv4_address FROM previous), ''),
+				     COALESCE((SELECT ipv4_address_int FROM previous), 0)`
+
+	sqlError = db.QueryRow(sqlQuery,
+		record.MacAddress,
+		record.NetworkId,
+		record.MacAddress,
+		record.IPv4Address,
+		record.IPv4AddressInt,
+		record.SnmpCredential,
+		record.SnmpStatus,
+		record.Status,
+	).Scan(&record.Id, &previousIPv4Address, &previousIPv4AddressInt)
+
+	if sqlError != nil {
+		logging.Error("Failed to upsert device record; "+
+			"table: %s; nid: %v; mac: %s; ipv4: %s; ipv4int: %v; status: %v; error: %s;",
+			table, record.NetworkId, record.MacAddress, record.IPv4Address, record.IPv4AddressInt, record.Status,
+			sqlError.Error())
+		return false, record
+	}
+
+	if previousIPv4Address != "" && previousIPv4Address != record.IPv4Address {
+		history.InsertRecord(db, device.AddressChange{
+			DeviceType:        deviceType,
+			DeviceId:          record.Id,
+			MacAddress:        record.MacAddress,
+			OldIPv4Address:    previousIPv4Address,
+			OldIPv4AddressInt: previousIPv4AddressInt,
+			NewIPv4Address:    record.IPv4Address,
+			NewIPv4AddressInt: record.IPv4AddressInt,
+			Changed:           int(time.Now().Unix()),
+		})
+	}
+
+	dbDevice.RetireReplacedRecords(db, table, record)
+
+	return true, record
+}
+
+// upsertPostgresUnknownDevice stores a device whose MAC address is unknown by updating the unidentified record at the
+// same address, or inserting a new record when there is none.
+func upsertPostgresUnknownDevice(db *sql.DB, table string, record device.AccessPoint) (bool, device.AccessPoint) {
+	record.MacAddress = device.UnknownMacAddress
+
+	sqlQuery := `UPDATE ` + table + `
+				 SET network_id=?, ipv4_address_int=?, snmp_credential=?, snmp_status=?, status=?
+				 WHERE id = (SELECT id FROM ` + table + `
+							 WHERE ipv4_address = ? AND mac_address = '` + device.UnknownMacAddress + `'
+							 ORDER BY id
+							 LIMIT 1)
+				 RETURNING id`
+
+	sqlError := db.QueryRow(sqlQuery,
+		record.NetworkId,
+		record.IPv4AddressInt,
+		record.SnmpCredential,
+		record.SnmpStatus,
+		record.Status,
+		record.IPv4Address,
+	).Scan(&record.Id)
+
+	switch sqlError {
+	case nil:
+		return true, record
+	case sql.ErrNoRows:
+		return insertPostgresDevice(db, table, record)
+	default:
+		logging.Error("Failed to update device record; table: %s; ipv4: %s; error: %s;",
+			table, record.IPv4Address, sqlError.Error())
+		return false, record
+	}
+}
+
+func insertPostgresDevice(db *sql.DB, table string, record device.AccessPoint) (bool, device.AccessPoint) {
+	sqlQuery := `INSERT INTO ` + table + `(` + dbDevice.Columns + `)
+				 VALUES (?, ?, ?, ?, ?, ?, ?)
+				 RETURNING id`
+
+	sqlError := db.QueryRow(sqlQuery,
+		record.NetworkId,
+		record.MacAddress,
+		record.IPv4Address,
+		record.IPv4AddressInt,
+		record.SnmpCredential,
+		record.SnmpStatus,
+		record.Status,
+	).Scan(&record.Id)
+
+	if sqlError != nil {
+		logging.Error("Failed to create device record; "+
+			"table: %s; nid: %v; mac: %s; ipv4: %s; ipv4int: %v; status: %v; error: %s;",
+			table, record.NetworkId, record.MacAddress, record.IPv4Address, record.IPv4AddressInt, record.Status,
+			sqlError.Error())
+		return false, record
+	}
+
+	return true, record
+}
+
+type postgresScanRepository struct {
+	scanRepository
+}
+
+func (r postgresScanRepository) InsertRecord(record snmp.Scan) (bool, snmp.Scan) {
+	sqlQuery := `INSERT INTO snmp_scan(started, finished, status)
+			     VALUES (?, ?, ?)
+			     RETURNING id`
+
+	sqlError := r.db.QueryRow(sqlQuery, record.Started, record.Finished, record.Status).Scan(&record.Id)
+
+	if sqlError != nil {
+		logging.Error("Failed to create SNMP scan record; started: %v; status: %v; error: %s;",
+			record.Started, record.Status, sqlError.Error())
+		return false, record
+	}
+
+	return true, record
+}
+
+type postgresEventRepository struct {
+	eventRepository
+}
+
+func (r postgresEventRepository) InsertRecord(record snmp.Event) (bool, snmp.Event) {
+	sqlQuery := `INSERT INTO snmp_event(device_type, device_id, source_address, version, pdu_type, trap_oid, trap_name,
+							 uptime, received)
+			     VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+			     RETURNING id`
+
+	tx, sqlError := r.db.Begin()
+
+	if sqlError != nil {
+		logging.Error("Failed to begin SNMP event transaction; source: %s; trap: %s; error: %s;",
+			record.SourceAddress, record.TrapOid, sqlError.Error())
+		return false, record
+	}
+
+	sqlError = tx.QueryRow(sqlQuery, record.DeviceType, record.DeviceId, record.SourceAddress, record.Version,
+		record.PduType, record.TrapOid, record.TrapName, record.Uptime, record.Received).Scan(&record.Id)
+
+	if sqlError != nil {
+		logging.Error("Failed to create SNMP event record; source: %s; trap: %s; error: %s;",
+			record.SourceAddress, record.TrapOid, sqlError.Error())
+		return dbEvent.Commit(tx, false, record)
+	}
+
+	success, record := dbEvent.InsertValues(tx, record, r.maxParameters)
+
+	return dbEvent.Commit(tx, success, record)
+}
@@ -0,0 +1,191 @@
+package repository
+
+import (
+	dbGroup "as/camscan/internal/camscan/database/snmp/group"
+	dbOm "as/camscan/internal/camscan/database/snmp/om"
+	"as/camscan/internal/camscan/logging"
+	"as/camscan/internal/camscan/types/device"
+	"as/camscan/internal/camscan/types/network"
+	"as/camscan/internal/camscan/types/snmp"
+	"database/sql"
+	"fmt"
+)
+
+const DriverMySQL = "mysql"
+const DriverPostgres = "postgres"
+const DriverSQLite = "sqlite"
+
+// Clauses that skip the insert of a device whose MAC address the unique index of known MAC addresses already holds
+const skipKnownMacMySQL = "ON DUPLICATE KEY UPDATE id = id"
+const skipKnownMacSQLite = "ON CONFLICT (mac_address) WHERE mac_address <> '" + device.UnknownMacAddress +
+	"' DO NOTHING"
+
+// The most placeholders that a single statement may hold, which for SQLite is the limit of builds before 3.32.0
+const maxParametersMySQL = 65535
+const maxParametersPostgres = 65535
+const maxParametersSQLite = 999
+
+type SubnetRepository interface {
+	GetRecords() (bool, []network.Subnet)
+	UpsertRecord(record network.Subnet) (bool, network.Subnet)
+}
+
+type AccessPointRepository interface {
+	GetRecords() (bool, []device.AccessPoint)
+	GetRecordByMacAddress(mac string) (bool, device.AccessPoint)
+	GetRecordByIPv4Address(ipv4 string) (bool, device.AccessPoint)
+	UpsertRecord(record device.AccessPoint) (bool, device.AccessPoint)
+	InsertRecord(record device.AccessPoint) (bool, device.AccessPoint)
+	UpdateRecord(record device.AccessPoint) (bool, device.AccessPoint)
+	UpdateSnmpStatus(record device.AccessPoint) bool
+}
+
+type SubscriberModuleRepository interface {
+	GetRecords() (bool, []device.SubscriberModule)
+	GetRecordByMacAddress(mac string) (bool, device.SubscriberModule)
+	GetRecordByIPv4Address(ipv4 string) (bool, device.SubscriberModule)
+	UpsertRecord(record device.SubscriberModule) (bool, device.SubscriberModule)
+	InsertRecord(record device.SubscriberModule) (bool, device.SubscriberModule)
+	UpdateRecord(record device.SubscriberModule) (bool, device.SubscriberModule)
+	UpdateSnmpStatus(record device.SubscriberModule) bool
+}
+
+type RegistrationRepository interface {
+	GetRecords() (bool, []device.Registration)
+	UpsertRecord(record device.Registration) (bool, device.Registration)
+}
+
+type OidMapRepository interface {
+	GetRecords(deviceType int) (bool, []snmp.OidMap)
+	UpsertRecord(record snmp.OidMap) (bool, snmp.OidMap)
+}
+
+type PollGroupRepository interface {
+	GetRecords() (bool, []snmp.PollGroup)
+	UpsertRecord(record snmp.PollGroup) (bool, snmp.PollGroup)
+}
+
+type ScanRepository interface {
+	GetRecord(id int) (bool, snmp.Scan)
+	InsertRecord(record snmp.Scan) (bool, snmp.Scan)
+	UpdateRecord(record snmp.Scan) (bool, snmp.Scan)
+}
+
+type EventRepository interface {
+	GetRecordsByRange(from int, to int) (bool, []snmp.Event)
+	GetDeviceRecords(deviceType int, deviceId int, from int, to int) (bool, []snmp.Event)
+	InsertRecord(record snmp.Event) (bool, snmp.Event)
+}
+
+type FailureRepository interface {
+	GetScanRecords(scanId int) (bool, []snmp.PollFailure)
+	InsertRecords(records []snmp.PollFailure) bool
+}
+
+type RateRepository interface {
+	GetLatestRecords(deviceType int, deviceId int) (bool, []snmp.Rate)
+	GetScanRecords(scanId int) (bool, []snmp.Rate)
+	InsertRecords(records []snmp.Rate, batchSize int) bool
+}
+
+type ValueRepository interface {
+	GetLatestRecords(deviceType int, deviceId int) (bool, []snmp.Value)
+	GetLatestCounterRecords(deviceType int, since int) (bool, []snmp.Value)
+	GetRecordsByRange(deviceType int, deviceId int, oidMapId int, from int, to int) (bool, []snmp.Value)
+	GetScanRecords(scanId int) (bool, []snmp.Value)
+	GetBootRecords(deviceType int, oidMapId int, since int) (bool, []snmp.Value)
+	GetCaptureTimes(deviceType int, since int) (bool, []snmp.Value)
+	InsertRecords(records []snmp.Value, batchSize int) bool
+}
+
+// Repositories groups the storage repositories of a single database connection
+type Repositories struct {
+	Subnets           SubnetRepository
+	AccessPoints      AccessPointRepository
+	SubscriberModules SubscriberModuleRepository
+	Registrations     RegistrationRepository
+	OidMaps           OidMapRepository
+	PollGroups        PollGroupRepository
+	Scans             ScanRepository
+	Values            ValueRepository
+	Failures          FailureRepository
+	Rates             RateRepository
+	Events            EventRepository
+}
+
+// New creates the repositories for the given database connection using the SQL dialect of the given driver.
+func New(driver string, db *sql.DB) (*Repositories, error) {
+	maxParameters := maxParametersSQLite
+
+	switch driver {
+	case DriverMySQL:
+		maxParameters = maxParametersMySQL
+	case DriverPostgres:
+		maxParameters = maxParametersPostgres
+	}
+
+	repositories := &Repositories{
+		Scans:    scanRepository{db: db},
+		Values:   valueRepository{db: db, maxParameters: maxParameters},
+		Failures: failureRepository{db: db, maxParameters: maxParameters},
+		Rates:    rateRepository{db: db, maxParameters: maxParameters},
+		Events:   eventRepository{db: db, maxParameters: maxParameters},
+	}
+
+	switch driver {
+	case DriverMySQL:
+		repositories.AccessPoints = accessPointRepository{db: db, skipKnownMac: skipKnownMacMySQL}
+		repositories.SubscriberModules = subscriberModuleRepository{db: db, skipKnownMac: skipKnownMacMySQL}
+		repositories.Subnets = mysqlSubnetRepository{db: db}
+		repositories.Registrations = mysqlRegistrationRepository{db: db}
+		repositories.OidMaps = mysqlOidMapRepository{db: db}
+		repositories.PollGroups = mysqlPollGroupRepository{db: db}
+	case DriverPostgres:
+		// PostgreSQL doesn't report the IDs of inserted rows, so every insert of a row whose ID is needed differs
+		repositories.Subnets = conflictSubnetRepository{db: db}
+		repositories.AccessPoints = postgresAccessPointRepository{accessPointRepository{db: db}}
+		repositories.SubscriberModules = postgresSubscriberModuleRepository{subscriberModuleRepository{db: db}}
+		repositories.Registrations = conflictRegistrationRepository{db: db}
+		repositories.OidMaps = conflictOidMapRepository{db: db}
+		repositories.PollGroups = conflictPollGroupRepository{db: db}
+		repositories.Scans = postgresScanRepository{scanRepository{db: db}}
+		repositories.Events = postgresEventRepository{eventRepository{db: db, maxParameters: maxParameters}}
+	case DriverSQLite:
+		repositories.AccessPoints = accessPointRepository{db: db, skipKnownMac: skipKnownMacSQLite}
+		repositories.SubscriberModules = subscriberModuleRepository{db: db, skipKnownMac: skipKnownMacSQLite}
+		repositories.Subnets = conflictSubnetRepository{db: db}
+		repositories.Registrations = conflictRegistrationRepository{db: db}
+		repositories.OidMaps = conflictOidMapRepository{db: db}
+		repositories.PollGroups = conflictPollGroupRepository{db: db}
+	default:
+		return nil, fmt.Errorf("unsupported database driver '%s'", driver)
+	}
+
+	return repositories, nil
+}
+
+// SeedPollGroups stores the poll groups used by the default OID map, updating the intervals of existing groups.
+func SeedPollGroups(pollGroups PollGroupRepository) bool {
+	for _, record := range dbGroup.DefaultRecords {
+		if success, _ := pollGroups.UpsertRecord(record); success != true {
+			return false
+		}
+	}
+
+	logging.Info("Seeded %v SNMP poll group records.", len(dbGroup.DefaultRecords))
+
+	return true
+}
+
+// SeedOidMaps stores the default Cambium OID map, updating any existing entries with the same key names.
+func SeedOidMaps(oidMaps OidMapRepository) bool {
+	for _, record := range dbOm.DefaultRecords {
+		if success, _ := oidMaps.UpsertRecord(record); success != true {
+			return false
+		}
+	}
+
+	logging.Info("Seeded %v SNMP OID map records.", len(dbOm.DefaultRecords))
+
+	return true
+}
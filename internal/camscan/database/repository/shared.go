@@ -0,0 +1,180 @@
+package repository
+
+import (
+	dbAp "as/camscan/internal/camscan/database/device/ap"
+	dbSm "as/camscan/internal/camscan/database/device/sm"
+	dbEvent "as/camscan/internal/camscan/database/snmp/event"
+	dbFailure "as/camscan/internal/camscan/database/snmp/failure"
+	dbRate "as/camscan/internal/camscan/database/snmp/rate"
+	dbScan "as/camscan/internal/camscan/database/snmp/scan"
+	dbValue "as/camscan/internal/camscan/database/snmp/value"
+	"as/camscan/internal/camscan/types/device"
+	"as/camscan/internal/camscan/types/snmp"
+	"database/sql"
+)
+
+// The device, scan, value, rate, failure and event queries only use SQL that every driver understands once the
+// PostgreSQL driver has rewritten the placeholders, so the drivers share them
+
+type accessPointRepository struct {
+	db           *sql.DB
+	skipKnownMac string
+}
+
+func (r accessPointRepository) GetRecords() (bool, []device.AccessPoint) {
+	return dbAp.GetRecords(r.db)
+}
+
+func (r accessPointRepository) GetRecordByMacAddress(mac string) (bool, device.AccessPoint) {
+	return dbAp.GetRecordByMacAddress(r.db, mac)
+}
+
+func (r accessPointRepository) GetRecordByIPv4Address(ipv4 string) (bool, device.AccessPoint) {
+	return dbAp.GetRecordByIPv4Address(r.db, ipv4)
+}
+
+func (r accessPointRepository) UpsertRecord(record device.AccessPoint) (bool, device.AccessPoint) {
+	return dbAp.UpsertRecord(r.db, record, r.skipKnownMac)
+}
+
+func (r accessPointRepository) InsertRecord(record device.AccessPoint) (bool, device.AccessPoint) {
+	return dbAp.InsertRecord(r.db, record)
+}
+
+func (r accessPointRepository) UpdateRecord(record device.AccessPoint) (bool, device.AccessPoint) {
+	return dbAp.UpdateRecord(r.db, record)
+}
+
+func (r accessPointRepository) UpdateSnmpStatus(record device.AccessPoint) bool {
+	return dbAp.UpdateSnmpStatus(r.db, record)
+}
+
+type subscriberModuleRepository struct {
+	db           *sql.DB
+	skipKnownMac string
+}
+
+func (r subscriberModuleRepository) GetRecords() (bool, []device.SubscriberModule) {
+	return dbSm.GetRecords(r.db)
+}
+
+func (r subscriberModuleRepository) GetRecordByMacAddress(mac string) (bool, device.SubscriberModule) {
+	return dbSm.GetRecordByMacAddress(r.db, mac)
+}
+
+func (r subscriberModuleRepository) GetRecordByIPv4Address(ipv4 string) (bool, device.SubscriberModule) {
+	return dbSm.GetRecordByIPv4Address(r.db, ipv4)
+}
+
+func (r subscriberModuleRepository) UpsertRecord(record device.SubscriberModule) (bool, device.SubscriberModule) {
+	return dbSm.UpsertRecord(r.db, record, r.skipKnownMac)
+}
+
+func (r subscriberModuleRepository) InsertRecord(record device.SubscriberModule) (bool, device.SubscriberModule) {
+	return dbSm.InsertRecord(r.db, record)
+}
+
+func (r subscriberModuleRepository) UpdateRecord(record device.SubscriberModule) (bool, device.SubscriberModule) {
+	return dbSm.UpdateRecord(r.db, record)
+}
+
+func (r subscriberModuleRepository) UpdateSnmpStatus(record device.SubscriberModule) bool {
+	return dbSm.UpdateSnmpStatus(r.db, record)
+}
+
+type scanRepository struct {
+	db *sql.DB
+}
+
+func (r scanRepository) GetRecord(id int) (bool, snmp.Scan) {
+	return dbScan.GetRecord(r.db, id)
+}
+
+func (r scanRepository) InsertRecord(record snmp.Scan) (bool, snmp.Scan) {
+	return dbScan.InsertRecord(r.db, record)
+}
+
+func (r scanRepository) UpdateRecord(record snmp.Scan) (bool, snmp.Scan) {
+	return dbScan.UpdateRecord(r.db, record)
+}
+
+type valueRepository struct {
+	db            *sql.DB
+	maxParameters int
+}
+
+func (r valueRepository) GetLatestRecords(deviceType int, deviceId int) (bool, []snmp.Value) {
+	return dbValue.GetLatestRecords(r.db, deviceType, deviceId)
+}
+
+func (r valueRepository) GetLatestCounterRecords(deviceType int, since int) (bool, []snmp.Value) {
+	return dbValue.GetLatestCounterRecords(r.db, deviceType, since)
+}
+
+func (r valueRepository) GetRecordsByRange(deviceType int, deviceId int, oidMapId int, from int,
+	to int) (bool, []snmp.Value) {
+	return dbValue.GetRecordsByRange(r.db, deviceType, deviceId, oidMapId, from, to)
+}
+
+func (r valueRepository) GetScanRecords(scanId int) (bool, []snmp.Value) {
+	return dbValue.GetScanRecords(r.db, scanId)
+}
+
+func (r valueRepository) GetBootRecords(deviceType int, oidMapId int, since int) (bool, []snmp.Value) {
+	return dbValue.GetBootRecords(r.db, deviceType, oidMapId, since)
+}
+
+func (r valueRepository) GetCaptureTimes(deviceType int, since int) (bool, []snmp.Value) {
+	return dbValue.GetCaptureTimes(r.db, deviceType, since)
+}
+
+func (r valueRepository) InsertRecords(records []snmp.Value, batchSize int) bool {
+	return dbValue.InsertRecords(r.db, records, batchSize, r.maxParameters)
+}
+
+type failureRepository struct {
+	db            *sql.DB
+	maxParameters int
+}
+
+func (r failureRepository) GetScanRecords(scanId int) (bool, []snmp.PollFailure) {
+	return dbFailure.GetScanRecords(r.db, scanId)
+}
+
+func (r failureRepository) InsertRecords(records []snmp.PollFailure) bool {
+	return dbFailure.InsertRecords(r.db, records, r.maxParameters)
+}
+
+type rateRepository struct {
+	db            *sql.DB
+	maxParameters int
+}
+
+func (r rateRepository) GetLatestRecords(deviceType int, deviceId int) (bool, []snmp.Rate) {
+	return dbRate.GetLatestRecords(r.db, deviceType, deviceId)
+}
+
+func (r rateRepository) GetScanRecords(scanId int) (bool, []snmp.Rate) {
+	return dbRate.GetScanRecords(r.db, scanId)
+}
+
+func (r rateRepository) InsertRecords(records []snmp.Rate, batchSize int) bool {
+	return dbRate.InsertRecords(r.db, records, batchSize, r.maxParameters)
+}
+
+type eventRepository struct {
+	db            *sql.DB
+	maxParameters int
+}
+
+func (r eventRepository) GetRecordsByRange(from int, to int) (bool, []snmp.Event) {
+	return dbEvent.GetRecordsByRange(r.db, from, to)
+}
+
+func (r eventRepository) GetDeviceRecords(deviceType int, deviceId int, from int, to int) (bool, []snmp.Event) {
+	return dbEvent.GetDeviceRecords(r.db, deviceType, deviceId, from, to)
+}
+
+func (r eventRepository) InsertRecord(record snmp.Event) (bool, snmp.Event) {
+	return dbEvent.InsertRecord(r.db, record, r.maxParameters)
+}
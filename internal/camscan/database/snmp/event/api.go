@@ -0,0 +1,207 @@
+package event
+
+import (
+	"as/camscan/internal/camscan/logging"
+	"as/camscan/internal/camscan/types/snmp"
+	"database/sql"
+	"strings"
+)
+
+// The number of columns, and so of placeholders per row, of the multi-row inserts of event values
+const insertColumns = 8
+
+const selectColumns = `e.id, e.device_type, e.device_id, e.source_address, e.version, e.pdu_type, e.trap_oid,
+						e.trap_name, e.uptime, e.received`
+
+const selectValueColumns = `ev.id, ev.event_id, ev.value_oid, ev.value_name, ev.snmp_type, ev.snmp_value_char,
+							ev.snmp_value_num, ev.snmp_value_text, ev.unit`
+
+// GetRecordsByRange retrieves every event received between the given unix timestamps along with its values.
+func GetRecordsByRange(db *sql.DB, from int, to int) (bool, []snmp.Event) {
+	return queryRecords(db, `e.received BETWEEN ? AND ?`, from, to)
+}
+
+// GetDeviceRecords retrieves the events of the given device received between the given unix timestamps along with
+// their values. Events from unknown sources are retrieved with a device type and ID of zero.
+func GetDeviceRecords(db *sql.DB, deviceType int, deviceId int, from int, to int) (bool, []snmp.Event) {
+	return queryRecords(db, `e.device_type = ? AND e.device_id = ? AND e.received BETWEEN ? AND ?`,
+		deviceType, deviceId, from, to)
+}
+
+// queryRecords retrieves the events that match the given condition, then retrieves the values of those events with a
+// second query using the same condition
+func queryRecords(db *sql.DB, condition string, args ...interface{}) (bool, []snmp.Event) {
+	var records []snmp.Event
+
+	sqlQuery := `SELECT ` + selectColumns + `
+				 FROM snmp_event e
+				 WHERE ` + condition + `
+				 ORDER BY e.received, e.id`
+
+	sqlResults, sqlError := db.Query(sqlQuery, args...)
+
+	if sqlError != nil {
+		logging.Error("Error retrieving SNMP event records from database; error: %s;", sqlError.Error())
+		return false, records
+	}
+
+	defer func(sqlResults *sql.Rows) {
+		_ = sqlResults.Close()
+	}(sqlResults)
+
+	positions := make(map[int]int)
+
+	for sqlResults.Next() {
+		var record snmp.Event
+		_ = sqlResults.Scan(&record.Id, &record.DeviceType, &record.DeviceId, &record.SourceAddress, &record.Version,
+			&record.PduType, &record.TrapOid, &record.TrapName, &record.Uptime, &record.Received)
+
+		positions[record.Id] = len(records)
+		records = append(records, record)
+	}
+
+	if len(records) == 0 {
+		return true, records
+	}
+
+	sqlQuery = `SELECT ` + selectValueColumns + `
+				FROM snmp_event_value ev
+				INNER JOIN snmp_event e ON e.id = ev.event_id
+				WHERE ` + condition + `
+				ORDER BY ev.event_id, ev.id`
+
+	valueResults, sqlError := db.Query(sqlQuery, args...)
+
+	if sqlError != nil {
+		logging.Error("Error retrieving SNMP event value records from database; error: %s;", sqlError.Error())
+		return false, records
+	}
+
+	defer func(valueResults *sql.Rows) {
+		_ = valueResults.Close()
+	}(valueResults)
+
+	for valueResults.Next() {
+		var record snmp.EventValue
+		_ = valueResults.Scan(&record.Id, &record.EventId, &record.Oid, &record.Name, &record.Value.SnmpType,
+			&record.Value.SnmpValueChar, &record.Value.SnmpValueNum, &record.Value.SnmpValueText, &record.Value.Unit)
+
+		if position, ok := positions[record.EventId]; ok {
+			records[position].Values = append(records[position].Values, record)
+		}
+	}
+
+	return true, records
+}
+
+// InsertRecord stores the given event, then its values.
+// InsertRecord stores the given event along with its values in a single transaction, so that no event is stored
+// without its values.
+func InsertRecord(db *sql.DB, record snmp.Event, maxParameters int) (bool, snmp.Event) {
+	sqlQuery := `INSERT INTO snmp_event(device_type, device_id, source_address, version, pdu_type, trap_oid, trap_name,
+							 uptime, received)
+			     VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`
+
+	tx, sqlError := db.Begin()
+
+	if sqlError != nil {
+		logging.Error("Failed to begin SNMP event transaction; source: %s; trap: %s; error: %s;",
+			record.SourceAddress, record.TrapOid, sqlError.Error())
+		return false, record
+	}
+
+	sqlResult, sqlError := tx.Exec(sqlQuery, record.DeviceType, record.DeviceId, record.SourceAddress, record.Version,
+		record.PduType, record.TrapOid, record.TrapName, record.Uptime, record.Received)
+
+	if sqlError != nil {
+		logging.Error("Failed to create SNMP event record; source: %s; trap: %s; error: %s;",
+			record.SourceAddress, record.TrapOid, sqlError.Error())
+		return Commit(tx, false, record)
+	}
+
+	id, sqlError := sqlResult.LastInsertId()
+
+	if sqlError != nil {
+		logging.Error("Failed to retrieve SNMP event record ID; source: %s; trap: %s; error: %s;",
+			record.SourceAddress, record.TrapOid, sqlError.Error())
+		return Commit(tx, false, record)
+	}
+
+	record.Id = int(id)
+	success, record := InsertValues(tx, record, maxParameters)
+
+	return Commit(tx, success, record)
+}
+
+// Commit ends the transaction that the given event was stored in, committing it when every insert succeeded and
+// rolling it back otherwise.
+func Commit(tx *sql.Tx, success bool, record snmp.Event) (bool, snmp.Event) {
+	if success != true {
+		_ = tx.Rollback()
+		return false, record
+	}
+
+	if sqlError := tx.Commit(); sqlError != nil {
+		logging.Error("Failed to commit SNMP event record; source: %s; trap: %s; error: %s;",
+			record.SourceAddress, record.TrapOid, sqlError.Error())
+		return false, record
+	}
+
+	return true, record
+}
+
+// InsertValues stores the values of the given, already stored, event within the event's transaction using as few
+// multi-row inserts as the placeholders allow, where maxParameters is the most that the database allows in a single
+// statement.
+func InsertValues(tx *sql.Tx, record snmp.Event, maxParameters int) (bool, snmp.Event) {
+	batchSize := maxParameters / insertColumns
+
+	if batchSize < 1 {
+		batchSize = 1
+	}
+
+	for i := range record.Values {
+		record.Values[i].EventId = record.Id
+	}
+
+	for start := 0; start < len(record.Values); start += batchSize {
+		end := start + batchSize
+
+		if end > len(record.Values) {
+			end = len(record.Values)
+		}
+
+		if !insertValueBatch(tx, record.Id, record.Values[start:end]) {
+			return false, record
+		}
+	}
+
+	logging.Trace1("Created SNMP event record; id: %v; values: %v;", record.Id, len(record.Values))
+
+	return true, record
+}
+
+func insertValueBatch(tx *sql.Tx, eventId int, values []snmp.EventValue) bool {
+	placeholders := make([]string, 0, len(values))
+	args := make([]interface{}, 0, len(values)*insertColumns)
+
+	for _, value := range values {
+		placeholders = append(placeholders, "(?, ?, ?, ?, ?, ?, ?, ?)")
+		args = append(args, value.EventId, value.Oid, value.Name, value.Value.SnmpType, value.Value.SnmpValueChar,
+			value.Value.SnmpValueNum, value.Value.SnmpValueText, value.Value.Unit)
+	}
+
+	sqlQuery := `INSERT INTO snmp_event_value(event_id, value_oid, value_name, snmp_type, snmp_value_char,
+							 snmp_value_num, snmp_value_text, unit)
+			     VALUES ` + strings.Join(placeholders, ", ")
+
+	_, sqlError := tx.Exec(sqlQuery, args...)
+
+	if sqlError != nil {
+		logging.Error("Failed to create SNMP event value records; event: %v; records: %v; error: %s;",
+			eventId, len(values), sqlError.Error())
+		return false
+	}
+
+	return true
+}
@@ -0,0 +1,94 @@
+package failure
+
+import (
+	"as/camscan/internal/camscan/logging"
+	"as/camscan/internal/camscan/types/snmp"
+	"database/sql"
+	"strings"
+)
+
+// The number of columns, and so of placeholders per row, of the multi-row inserts
+const insertColumns = 7
+
+// GetScanRecords retrieves the final poll failures recorded by the given scan.
+func GetScanRecords(db *sql.DB, scanId int) (bool, []snmp.PollFailure) {
+	var records []snmp.PollFailure
+	var sqlQuery = `SELECT id, scan_id, device_type, device_id, reason, attempts, message, recorded
+					FROM snmp_poll_failure
+					WHERE scan_id = ?
+					ORDER BY device_type, device_id`
+
+	sqlResults, sqlError := db.Query(sqlQuery, scanId)
+
+	if sqlError != nil {
+		logging.Error("Error retrieving SNMP poll failure records from database; error: %s;", sqlError.Error())
+		return false, records
+	}
+
+	defer func(sqlResults *sql.Rows) {
+		_ = sqlResults.Close()
+	}(sqlResults)
+
+	for sqlResults.Next() {
+		var record snmp.PollFailure
+		_ = sqlResults.Scan(&record.Id, &record.ScanId, &record.DeviceType, &record.DeviceId, &record.Reason,
+			&record.Attempts, &record.Message, &record.Recorded)
+
+		records = append(records, record)
+	}
+
+	return true, records
+}
+
+// InsertRecords stores the given poll failures using as few multi-row inserts as the placeholders allow, where
+// maxParameters is the most that the database allows in a single statement.
+func InsertRecords(db *sql.DB, records []snmp.PollFailure, maxParameters int) bool {
+	batchSize := maxParameters / insertColumns
+
+	if batchSize < 1 {
+		batchSize = 1
+	}
+
+	for start := 0; start < len(records); start += batchSize {
+		end := start + batchSize
+
+		if end > len(records) {
+			end = len(records)
+		}
+
+		if !insertBatch(db, records[start:end]) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func insertBatch(db *sql.DB, records []snmp.PollFailure) bool {
+	placeholders := make([]string, 0, len(records))
+	args := make([]interface{}, 0, len(records)*insertColumns)
+
+	for _, record := range records {
+		// Keep the message within the column's length
+		if len(record.Message) > snmp.MaxValueCharLength {
+			record.Message = record.Message[:snmp.MaxValueCharLength]
+		}
+
+		placeholders = append(placeholders, "(?, ?, ?, ?, ?, ?, ?)")
+		args = append(args, record.ScanId, record.DeviceType, record.DeviceId, record.Reason, record.Attempts,
+			record.Message, record.Recorded)
+	}
+
+	sqlQuery := `INSERT INTO snmp_poll_failure(scan_id, device_type, device_id, reason, attempts, message, recorded)
+			     VALUES ` + strings.Join(placeholders, ", ")
+
+	_, sqlError := db.Exec(sqlQuery, args...)
+
+	if sqlError != nil {
+		logging.Error("Failed to create SNMP poll failure records; scan: %v; records: %v; error: %s;",
+			records[0].ScanId, len(records), sqlError.Error())
+		return false
+	}
+
+	return true
+}
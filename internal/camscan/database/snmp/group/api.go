@@ -0,0 +1,53 @@
+package group
+
+import (
+	"as/camscan/internal/camscan/logging"
+	"as/camscan/internal/camscan/types/snmp"
+	"database/sql"
+)
+
+func GetRecords(db *sql.DB) (bool, []snmp.PollGroup) {
+	var records []snmp.PollGroup
+	var sqlQuery = `SELECT spg.id, spg.name, spg.poll_interval
+					FROM snmp_poll_group spg
+					ORDER BY spg.name`
+
+	sqlResults, sqlError := db.Query(sqlQuery)
+
+	if sqlError != nil {
+		logging.Error("Error retrieving SNMP poll group records from database; error: %s;", sqlError.Error())
+		return false, records
+	}
+
+	defer func(sqlResults *sql.Rows) {
+		_ = sqlResults.Close()
+	}(sqlResults)
+
+	for sqlResults.Next() {
+		var record snmp.PollGroup
+		_ = sqlResults.Scan(&record.Id, &record.Name, &record.Interval)
+
+		records = append(records, record)
+
+		logging.Trace1("SNMP poll group record loaded; id: %v; name: %s; interval: %v;",
+			record.Id, record.Name, record.Interval)
+	}
+
+	return true, records
+}
+
+func UpsertRecord(db *sql.DB, record snmp.PollGroup) (bool, snmp.PollGroup) {
+	sqlQuery := `INSERT INTO snmp_poll_group(name, poll_interval)
+			     VALUES (?, ?)
+				 ON DUPLICATE KEY UPDATE poll_interval=?`
+
+	_, sqlError := db.Exec(sqlQuery, record.Name, record.Interval, record.Interval)
+
+	if sqlError != nil {
+		logging.Error("Failed to create SNMP poll group record; name: %s; interval: %v; error: %s;",
+			record.Name, record.Interval, sqlError.Error())
+		return false, record
+	}
+
+	return true, record
+}
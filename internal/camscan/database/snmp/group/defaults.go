@@ -0,0 +1,12 @@
+package group
+
+import "as/camscan/internal/camscan/types/snmp"
+
+// DefaultRecords defines the poll groups used by the default OID map
+var DefaultRecords = []snmp.PollGroup{
+	// Device state that changes constantly is polled every scan cycle
+	{Name: "status", Interval: 0},
+	{Name: "interfaces", Interval: 900},
+	// Firmware versions, MAC addresses and site names rarely change
+	{Name: "inventory", Interval: 3600},
+}
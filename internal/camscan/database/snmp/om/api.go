@@ -8,10 +8,11 @@ import (
 
 func GetRecords(db *sql.DB, deviceType int) (bool, []snmp.OidMap) {
 	var records []snmp.OidMap
-	var sqlQuery = `SELECT som.id, som.device_type, som.key_name, som.oid, som.order
-					FROM snmp_oid_map som
-					WHERE som.device_type = ?
-					ORDER BY som.order`
+	var sqlQuery = "SELECT som.id, som.device_type, som.key_name, som.oid, som.kind, som.`order`, som.poll_group, " +
+		"som.scale, som.value_offset, som.pattern, som.enum_labels, som.unit " +
+		"FROM snmp_oid_map som " +
+		"WHERE som.device_type = ? " +
+		"ORDER BY som.`order`"
 
 	sqlResults, sqlError := db.Query(sqlQuery, deviceType)
 
@@ -20,17 +21,77 @@ func GetRecords(db *sql.DB, deviceType int) (bool, []snmp.OidMap) {
 			sqlError.Error())
 		return false, records
 	} else {
+		defer func(sqlResults *sql.Rows) {
+			_ = sqlResults.Close()
+		}(sqlResults)
+
 		for sqlResults.Next() {
 			var record snmp.OidMap
-			_ = sqlResults.Scan(&record.Id, &record.DeviceType, &record.KeyName, &record.Oid, &record.Order)
+			_ = sqlResults.Scan(&record.Id, &record.DeviceType, &record.KeyName, &record.Oid, &record.Kind, &record.Order,
+				&record.PollGroup, &record.Transform.Scale, &record.Transform.Offset, &record.Transform.Pattern,
+				&record.Transform.Enum, &record.Transform.Unit)
+
+			if record.Kind == "" {
+				record.Kind = snmp.OidKindScalar
+			}
+
+			// A bad transform only costs its own entry, so the rest of the map is still polled
+			if err := record.Transform.Compile(); err != nil {
+				logging.Error("Skipping SNMP OID map record with an invalid transform; "+
+					"id: %v; type: %v; key: %s; error: %s;", record.Id, record.DeviceType, record.KeyName, err.Error())
+				continue
+			}
 
 			records = append(records, record)
 
 			logging.Trace1("SNMP OID map record loaded; "+
-				"id: %v; type: %s; key: %s; oid: %s; order: %v;",
-				record.Id, record.DeviceType, record.KeyName, record.Oid, record.Order)
+				"id: %v; type: %v; key: %s; oid: %s; kind: %s; order: %v; group: %s; scale: %v; offset: %v; "+
+				"pattern: %s; enum: %s; unit: %s;",
+				record.Id, record.DeviceType, record.KeyName, record.Oid, record.Kind, record.Order, record.PollGroup,
+				record.Transform.Factor(), record.Transform.Offset, record.Transform.Pattern, record.Transform.Enum,
+				record.Transform.Unit)
 		}
 	}
 
 	return true, records
 }
+
+func UpsertRecord(db *sql.DB, record snmp.OidMap) (bool, snmp.OidMap) {
+	sqlQuery := "INSERT INTO snmp_oid_map(device_type, key_name, oid, kind, `order`, poll_group, scale, value_offset, " +
+		"pattern, enum_labels, unit) " +
+		"VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?) " +
+		"ON DUPLICATE KEY UPDATE oid=?, kind=?, `order`=?, poll_group=?, scale=?, value_offset=?, pattern=?, " +
+		"enum_labels=?, unit=?"
+
+	_, sqlError := db.Exec(sqlQuery,
+		record.DeviceType,
+		record.KeyName,
+		record.Oid,
+		record.Kind,
+		record.Order,
+		record.PollGroup,
+		record.Transform.Factor(),
+		record.Transform.Offset,
+		record.Transform.Pattern,
+		record.Transform.Enum,
+		record.Transform.Unit,
+		record.Oid,
+		record.Kind,
+		record.Order,
+		record.PollGroup,
+		record.Transform.Factor(),
+		record.Transform.Offset,
+		record.Transform.Pattern,
+		record.Transform.Enum,
+		record.Transform.Unit,
+	)
+
+	if sqlError != nil {
+		logging.Error("Failed to create SNMP OID map record; "+
+			"type: %v; key: %s; oid: %s; kind: %s; order: %v; error: %s;",
+			record.DeviceType, record.KeyName, record.Oid, record.Kind, record.Order, sqlError.Error())
+		return false, record
+	}
+
+	return true, record
+}
@@ -0,0 +1,163 @@
+package om
+
+import "as/camscan/internal/camscan/types/snmp"
+
+// DefaultRecords defines the default OID map for Cambium PMP access points and subscriber modules
+var DefaultRecords = []snmp.OidMap{
+	// Access Points
+	{
+		DeviceType: snmp.DeviceTypeAccessPoint,
+		KeyName:    "system_description",
+		Oid:        "1.3.6.1.2.1.1.1.0",
+		Kind:       snmp.OidKindScalar,
+		Order:      10,
+		PollGroup:  "inventory",
+	},
+	{
+		DeviceType: snmp.DeviceTypeAccessPoint,
+		KeyName:    "system_uptime",
+		Oid:        "1.3.6.1.2.1.1.3.0",
+		Kind:       snmp.OidKindScalar,
+		Order:      20,
+		PollGroup:  "status",
+	},
+	{
+		DeviceType: snmp.DeviceTypeAccessPoint,
+		KeyName:    "system_name",
+		Oid:        "1.3.6.1.2.1.1.5.0",
+		Kind:       snmp.OidKindScalar,
+		Order:      30,
+		PollGroup:  "inventory",
+	},
+	{
+		DeviceType: snmp.DeviceTypeAccessPoint,
+		KeyName:    "system_location",
+		Oid:        "1.3.6.1.2.1.1.6.0",
+		Kind:       snmp.OidKindScalar,
+		Order:      40,
+		PollGroup:  "inventory",
+	},
+	{
+		DeviceType: snmp.DeviceTypeAccessPoint,
+		KeyName:    "software_version",
+		Oid:        "1.3.6.1.4.1.161.19.3.3.1.1.0",
+		Kind:       snmp.OidKindScalar,
+		Order:      50,
+		PollGroup:  "inventory",
+	},
+	{
+		DeviceType: snmp.DeviceTypeAccessPoint,
+		KeyName:    "mac_address",
+		Oid:        "1.3.6.1.4.1.161.19.3.3.1.3.0",
+		Kind:       snmp.OidKindScalar,
+		Order:      60,
+		PollGroup:  "inventory",
+	},
+	{
+		DeviceType: snmp.DeviceTypeAccessPoint,
+		KeyName:    "registered_sms",
+		Oid:        "1.3.6.1.4.1.161.19.3.1.7.1.0",
+		Kind:       snmp.OidKindScalar,
+		Order:      70,
+		PollGroup:  "status",
+	},
+	{
+		DeviceType: snmp.DeviceTypeAccessPoint,
+		KeyName:    "interfaces",
+		Oid:        "1.3.6.1.2.1.2.2.1",
+		Kind:       snmp.OidKindTable,
+		Order:      100,
+		PollGroup:  "interfaces",
+	},
+	// WHISP-APS-MIB::whispLinkEntry, whose registered subscriber modules are polled grouped by access point
+	{
+		DeviceType: snmp.DeviceTypeAccessPoint,
+		KeyName:    "links",
+		Oid:        "1.3.6.1.4.1.161.19.3.1.4.1",
+		Kind:       snmp.OidKindTable,
+		Order:      110,
+		PollGroup:  "inventory",
+	},
+
+	// Subscriber Modules
+	{
+		DeviceType: snmp.DeviceTypeSubscriberModule,
+		KeyName:    "system_description",
+		Oid:        "1.3.6.1.2.1.1.1.0",
+		Kind:       snmp.OidKindScalar,
+		Order:      10,
+		PollGroup:  "inventory",
+	},
+	{
+		DeviceType: snmp.DeviceTypeSubscriberModule,
+		KeyName:    "system_uptime",
+		Oid:        "1.3.6.1.2.1.1.3.0",
+		Kind:       snmp.OidKindScalar,
+		Order:      20,
+		PollGroup:  "status",
+	},
+	{
+		DeviceType: snmp.DeviceTypeSubscriberModule,
+		KeyName:    "system_name",
+		Oid:        "1.3.6.1.2.1.1.5.0",
+		Kind:       snmp.OidKindScalar,
+		Order:      30,
+		PollGroup:  "inventory",
+	},
+	{
+		DeviceType: snmp.DeviceTypeSubscriberModule,
+		KeyName:    "system_location",
+		Oid:        "1.3.6.1.2.1.1.6.0",
+		Kind:       snmp.OidKindScalar,
+		Order:      40,
+		PollGroup:  "inventory",
+	},
+	{
+		DeviceType: snmp.DeviceTypeSubscriberModule,
+		KeyName:    "software_version",
+		Oid:        "1.3.6.1.4.1.161.19.3.3.1.1.0",
+		Kind:       snmp.OidKindScalar,
+		Order:      50,
+		PollGroup:  "inventory",
+	},
+	{
+		DeviceType: snmp.DeviceTypeSubscriberModule,
+		KeyName:    "mac_address",
+		Oid:        "1.3.6.1.4.1.161.19.3.3.1.3.0",
+		Kind:       snmp.OidKindScalar,
+		Order:      60,
+		PollGroup:  "inventory",
+	},
+	{
+		DeviceType: snmp.DeviceTypeSubscriberModule,
+		KeyName:    "rssi",
+		Oid:        "1.3.6.1.4.1.161.19.3.2.2.2.0",
+		Kind:       snmp.OidKindScalar,
+		Order:      70,
+		PollGroup:  "status",
+	},
+	{
+		DeviceType: snmp.DeviceTypeSubscriberModule,
+		KeyName:    "jitter",
+		Oid:        "1.3.6.1.4.1.161.19.3.2.2.3.0",
+		Kind:       snmp.OidKindScalar,
+		Order:      80,
+		PollGroup:  "status",
+	},
+	{
+		DeviceType: snmp.DeviceTypeSubscriberModule,
+		KeyName:    "air_delay",
+		Oid:        "1.3.6.1.4.1.161.19.3.2.2.6.0",
+		Kind:       snmp.OidKindScalar,
+		Order:      90,
+		PollGroup:  "status",
+	},
+	{
+		DeviceType: snmp.DeviceTypeSubscriberModule,
+		KeyName:    "interfaces",
+		Oid:        "1.3.6.1.2.1.2.2.1",
+		Kind:       snmp.OidKindTable,
+		Order:      100,
+		PollGroup:  "interfaces",
+	},
+}
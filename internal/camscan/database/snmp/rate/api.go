@@ -0,0 +1,118 @@
+package rate
+
+import (
+	"as/camscan/internal/camscan/logging"
+	"as/camscan/internal/camscan/types/snmp"
+	"database/sql"
+	"strings"
+)
+
+// The number of columns, and so of placeholders per row, of the multi-row inserts
+const insertColumns = 8
+
+const selectColumns = `r.id, r.scan_id, r.device_type, r.device_id, r.oid_map_id, r.oid_index, r.rate, r.period,
+						r.captured`
+
+// GetLatestRecords retrieves the most recently computed rate of every counter of the given device. When deviceId is
+// zero, the latest rates of every device of the given type are retrieved instead.
+func GetLatestRecords(db *sql.DB, deviceType int, deviceId int) (bool, []snmp.Rate) {
+	var sqlQuery = `SELECT ` + selectColumns + `
+					FROM snmp_rate r
+					INNER JOIN (SELECT device_id, oid_map_id, oid_index, MAX(captured) AS captured
+								FROM snmp_rate
+								WHERE device_type = ? AND (? = 0 OR device_id = ?)
+								GROUP BY device_id, oid_map_id, oid_index) latest
+						ON latest.device_id = r.device_id AND latest.oid_map_id = r.oid_map_id
+							AND latest.oid_index = r.oid_index AND latest.captured = r.captured
+					WHERE r.device_type = ?
+					ORDER BY r.device_id, r.oid_map_id, r.oid_index`
+
+	return queryRecords(db, sqlQuery, deviceType, deviceId, deviceId, deviceType)
+}
+
+// GetScanRecords retrieves every rate computed by the given scan.
+func GetScanRecords(db *sql.DB, scanId int) (bool, []snmp.Rate) {
+	var sqlQuery = `SELECT ` + selectColumns + `
+					FROM snmp_rate r
+					WHERE r.scan_id = ?
+					ORDER BY r.device_type, r.device_id, r.oid_map_id, r.oid_index`
+
+	return queryRecords(db, sqlQuery, scanId)
+}
+
+func queryRecords(db *sql.DB, sqlQuery string, args ...interface{}) (bool, []snmp.Rate) {
+	var records []snmp.Rate
+
+	sqlResults, sqlError := db.Query(sqlQuery, args...)
+
+	if sqlError != nil {
+		logging.Error("Error retrieving SNMP rate records from database; error: %s;", sqlError.Error())
+		return false, records
+	}
+
+	defer func(sqlResults *sql.Rows) {
+		_ = sqlResults.Close()
+	}(sqlResults)
+
+	for sqlResults.Next() {
+		var record snmp.Rate
+		_ = sqlResults.Scan(&record.Id, &record.ScanId, &record.DeviceType, &record.DeviceId, &record.OidMapId,
+			&record.OidIndex, &record.Rate, &record.Period, &record.Captured)
+
+		records = append(records, record)
+	}
+
+	return true, records
+}
+
+// InsertRecords stores the given rates using multi-row inserts of up to batchSize rows each, or fewer when their
+// placeholders would exceed maxParameters, the most that the database allows in a single statement.
+func InsertRecords(db *sql.DB, records []snmp.Rate, batchSize int, maxParameters int) bool {
+	if batchSize > maxParameters/insertColumns {
+		batchSize = maxParameters / insertColumns
+	}
+
+	if batchSize < 1 {
+		batchSize = 1
+	}
+
+	for start := 0; start < len(records); start += batchSize {
+		end := start + batchSize
+
+		if end > len(records) {
+			end = len(records)
+		}
+
+		if !insertBatch(db, records[start:end]) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func insertBatch(db *sql.DB, records []snmp.Rate) bool {
+	placeholders := make([]string, 0, len(records))
+	args := make([]interface{}, 0, len(records)*insertColumns)
+
+	for _, record := range records {
+		placeholders = append(placeholders, "(?, ?, ?, ?, ?, ?, ?, ?)")
+		args = append(args, record.ScanId, record.DeviceType, record.DeviceId, record.OidMapId, record.OidIndex,
+			record.Rate, record.Period, record.Captured)
+	}
+
+	sqlQuery := `INSERT INTO snmp_rate(scan_id, device_type, device_id, oid_map_id, oid_index, rate, period, captured)
+			     VALUES ` + strings.Join(placeholders, ", ")
+
+	_, sqlError := db.Exec(sqlQuery, args...)
+
+	if sqlError != nil {
+		logging.Error("Failed to create SNMP rate records; scan: %v; records: %v; error: %s;",
+			records[0].ScanId, len(records), sqlError.Error())
+		return false
+	}
+
+	logging.Trace1("Created SNMP rate records; scan: %v; records: %v;", records[0].ScanId, len(records))
+
+	return true
+}
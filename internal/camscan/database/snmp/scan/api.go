@@ -0,0 +1,64 @@
+package scan
+
+import (
+	"as/camscan/internal/camscan/logging"
+	"as/camscan/internal/camscan/types/snmp"
+	"database/sql"
+)
+
+func GetRecord(db *sql.DB, id int) (bool, snmp.Scan) {
+	var record snmp.Scan
+	var sqlQuery = `SELECT id, started, finished, status
+					FROM snmp_scan
+					WHERE id = ?`
+
+	sqlError := db.QueryRow(sqlQuery, id).Scan(&record.Id, &record.Started, &record.Finished, &record.Status)
+
+	if sqlError != nil {
+		logging.Error("Error retrieving SNMP scan record from database; id: %v; error: %s;", id, sqlError.Error())
+		return false, record
+	}
+
+	return true, record
+}
+
+func InsertRecord(db *sql.DB, record snmp.Scan) (bool, snmp.Scan) {
+	sqlQuery := `INSERT INTO snmp_scan(started, finished, status)
+			     VALUES (?, ?, ?)`
+
+	sqlResult, sqlError := db.Exec(sqlQuery, record.Started, record.Finished, record.Status)
+
+	if sqlError != nil {
+		logging.Error("Failed to create SNMP scan record; started: %v; status: %v; error: %s;",
+			record.Started, record.Status, sqlError.Error())
+		return false, record
+	}
+
+	id, sqlError := sqlResult.LastInsertId()
+
+	if sqlError != nil {
+		logging.Error("Failed to retrieve SNMP scan record ID; started: %v; status: %v; error: %s;",
+			record.Started, record.Status, sqlError.Error())
+		return false, record
+	}
+
+	record.Id = int(id)
+
+	return true, record
+}
+
+func UpdateRecord(db *sql.DB, record snmp.Scan) (bool, snmp.Scan) {
+	sqlQuery := `UPDATE snmp_scan
+				 SET started=?, finished=?, status=?
+				 WHERE id = ?`
+
+	_, sqlError := db.Exec(sqlQuery, record.Started, record.Finished, record.Status, record.Id)
+
+	if sqlError != nil {
+		logging.Error("Failed to update SNMP scan record; id: %v; finished: %v; status: %v; error: %s;",
+			record.Id, record.Finished, record.Status, sqlError.Error())
+		return false, record
+	}
+
+	return true, record
+}
@@ -1 +1,205 @@
 package value
+
+import (
+	"as/camscan/internal/camscan/logging"
+	"as/camscan/internal/camscan/types/snmp"
+	"database/sql"
+	"strings"
+)
+
+// The number of columns, and so of placeholders per row, of the multi-row inserts
+const insertColumns = 12
+
+const selectColumns = `v.id, v.scan_id, v.device_type, v.device_id, v.oid_map_id, v.oid_index, v.snmp_type,
+       				v.snmp_value_char, v.snmp_value_num, v.snmp_value_text, v.unit, v.last_boot,
+       				v.captured`
+
+// GetLatestRecords retrieves the most recently captured value of every OID for the given device. When deviceId is
+// zero, the latest values of every device of the given type are retrieved instead.
+func GetLatestRecords(db *sql.DB, deviceType int, deviceId int) (bool, []snmp.Value) {
+	var sqlQuery = `SELECT ` + selectColumns + `
+					FROM snmp_value v
+					INNER JOIN (SELECT device_id, oid_map_id, oid_index, MAX(captured) AS captured
+								FROM snmp_value
+								WHERE device_type = ? AND (? = 0 OR device_id = ?)
+								GROUP BY device_id, oid_map_id, oid_index) latest
+						ON latest.device_id = v.device_id AND latest.oid_map_id = v.oid_map_id
+							AND latest.oid_index = v.oid_index AND latest.captured = v.captured
+					WHERE v.device_type = ?
+					ORDER BY v.device_id, v.oid_map_id, v.oid_index`
+
+	return queryRecords(db, sqlQuery, deviceType, deviceId, deviceId, deviceType)
+}
+
+// GetLatestCounterRecords retrieves the most recently captured sample of every counter of every device of the given
+// type that was captured at or after the given unix timestamp.
+func GetLatestCounterRecords(db *sql.DB, deviceType int, since int) (bool, []snmp.Value) {
+	var sqlQuery = `SELECT ` + selectColumns + `
+					FROM snmp_value v
+					INNER JOIN (SELECT device_id, oid_map_id, oid_index, MAX(captured) AS captured
+								FROM snmp_value
+								WHERE device_type = ? AND captured >= ? AND snmp_type IN (?, ?)
+								GROUP BY device_id, oid_map_id, oid_index) latest
+						ON latest.device_id = v.device_id AND latest.oid_map_id = v.oid_map_id
+							AND latest.oid_index = v.oid_index AND latest.captured = v.captured
+					WHERE v.device_type = ? AND v.captured >= ?
+					ORDER BY v.device_id, v.oid_map_id, v.oid_index`
+
+	return queryRecords(db, sqlQuery, deviceType, since, snmp.TypeCounter32, snmp.TypeCounter64, deviceType, since)
+}
+
+// GetRecordsByRange retrieves the values of the given device captured between the given unix timestamps, inclusive.
+// When oidMapId is zero, the values of every OID are retrieved.
+func GetRecordsByRange(db *sql.DB, deviceType int, deviceId int, oidMapId int, from int, to int) (bool, []snmp.Value) {
+	var sqlQuery = `SELECT ` + selectColumns + `
+					FROM snmp_value v
+					WHERE v.device_type = ? AND v.device_id = ? AND (? = 0 OR v.oid_map_id = ?)
+						AND v.captured BETWEEN ? AND ?
+					ORDER BY v.captured, v.oid_map_id, v.oid_index`
+
+	return queryRecords(db, sqlQuery, deviceType, deviceId, oidMapId, oidMapId, from, to)
+}
+
+// GetBootRecords retrieves the most recently captured value of the given TimeTicks OID for every device of the given
+// type that last booted at or after the given unix timestamp, e.g. the subscriber modules whose sysUpTime shows that
+// they rebooted in the last 24 hours.
+func GetBootRecords(db *sql.DB, deviceType int, oidMapId int, since int) (bool, []snmp.Value) {
+	var sqlQuery = `SELECT ` + selectColumns + `
+					FROM snmp_value v
+					INNER JOIN (SELECT device_id, oid_index, MAX(captured) AS captured
+								FROM snmp_value
+								WHERE device_type = ? AND oid_map_id = ?
+								GROUP BY device_id, oid_index) latest
+						ON latest.device_id = v.device_id AND latest.oid_index = v.oid_index
+							AND latest.captured = v.captured
+					WHERE v.device_type = ? AND v.oid_map_id = ? AND v.snmp_type = ? AND v.last_boot >= ?
+					ORDER BY v.last_boot DESC, v.device_id, v.oid_index`
+
+	return queryRecords(db, sqlQuery, deviceType, oidMapId, deviceType, oidMapId, snmp.TypeTimeTicks, since)
+}
+
+// GetScanRecords retrieves every value captured by the given scan.
+func GetScanRecords(db *sql.DB, scanId int) (bool, []snmp.Value) {
+	var sqlQuery = `SELECT ` + selectColumns + `
+					FROM snmp_value v
+					WHERE v.scan_id = ?
+					ORDER BY v.device_type, v.device_id, v.oid_map_id, v.oid_index`
+
+	return queryRecords(db, sqlQuery, scanId)
+}
+
+// GetCaptureTimes retrieves when each OID of every device of the given type was last captured, for the OIDs captured
+// at or after the given unix timestamp. Only the device ID, OID map ID and captured timestamp of the returned values
+// are set.
+func GetCaptureTimes(db *sql.DB, deviceType int, since int) (bool, []snmp.Value) {
+	var records []snmp.Value
+	var sqlQuery = `SELECT device_id, oid_map_id, MAX(captured)
+					FROM snmp_value
+					WHERE device_type = ? AND captured >= ?
+					GROUP BY device_id, oid_map_id`
+
+	sqlResults, sqlError := db.Query(sqlQuery, deviceType, since)
+
+	if sqlError != nil {
+		logging.Error("Error retrieving SNMP value capture times from database; error: %s;", sqlError.Error())
+		return false, records
+	}
+
+	defer func(sqlResults *sql.Rows) {
+		_ = sqlResults.Close()
+	}(sqlResults)
+
+	for sqlResults.Next() {
+		record := snmp.Value{DeviceType: deviceType}
+		_ = sqlResults.Scan(&record.DeviceId, &record.OidMapId, &record.Captured)
+
+		records = append(records, record)
+	}
+
+	return true, records
+}
+
+func queryRecords(db *sql.DB, sqlQuery string, args ...interface{}) (bool, []snmp.Value) {
+	var records []snmp.Value
+
+	sqlResults, sqlError := db.Query(sqlQuery, args...)
+
+	if sqlError != nil {
+		logging.Error("Error retrieving SNMP value records from database; error: %s;", sqlError.Error())
+		return false, records
+	}
+
+	defer func(sqlResults *sql.Rows) {
+		_ = sqlResults.Close()
+	}(sqlResults)
+
+	for sqlResults.Next() {
+		var record snmp.Value
+		_ = sqlResults.Scan(&record.Id, &record.ScanId, &record.DeviceType, &record.DeviceId, &record.OidMapId,
+			&record.OidIndex, &record.SnmpType, &record.SnmpValueChar, &record.SnmpValueNum, &record.SnmpValueText,
+			&record.Unit, &record.LastBoot, &record.Captured)
+
+		records = append(records, record)
+
+		logging.Trace1("SNMP value record loaded; "+
+			"id: %v; scan: %v; type: %v; did: %v; omid: %v; index: %s; value: %s; captured: %v;",
+			record.Id, record.ScanId, record.DeviceType, record.DeviceId, record.OidMapId, record.OidIndex,
+			record, record.Captured)
+	}
+
+	return true, records
+}
+
+// InsertRecords stores the given values using multi-row inserts of up to batchSize rows each, or fewer when their
+// placeholders would exceed maxParameters, the most that the database allows in a single statement.
+func InsertRecords(db *sql.DB, records []snmp.Value, batchSize int, maxParameters int) bool {
+	if batchSize > maxParameters/insertColumns {
+		batchSize = maxParameters / insertColumns
+	}
+
+	if batchSize < 1 {
+		batchSize = 1
+	}
+
+	for start := 0; start < len(records); start += batchSize {
+		end := start + batchSize
+
+		if end > len(records) {
+			end = len(records)
+		}
+
+		if !insertBatch(db, records[start:end]) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func insertBatch(db *sql.DB, records []snmp.Value) bool {
+	placeholders := make([]string, 0, len(records))
+	args := make([]interface{}, 0, len(records)*insertColumns)
+
+	for _, record := range records {
+		placeholders = append(placeholders, "(?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)")
+		args = append(args, record.ScanId, record.DeviceType, record.DeviceId, record.OidMapId, record.OidIndex,
+			record.SnmpType, record.SnmpValueChar, record.SnmpValueNum, record.SnmpValueText, record.Unit,
+			record.LastBoot, record.Captured)
+	}
+
+	sqlQuery := `INSERT INTO snmp_value(scan_id, device_type, device_id, oid_map_id, oid_index, snmp_type,
+                       snmp_value_char, snmp_value_num, snmp_value_text, unit, last_boot, captured)
+			     VALUES ` + strings.Join(placeholders, ", ")
+
+	_, sqlError := db.Exec(sqlQuery, args...)
+
+	if sqlError != nil {
+		logging.Error("Failed to create SNMP value records; scan: %v; records: %v; error: %s;",
+			records[0].ScanId, len(records), sqlError.Error())
+		return false
+	}
+
+	logging.Trace1("Created SNMP value records; scan: %v; records: %v;", records[0].ScanId, len(records))
+
+	return true
+}
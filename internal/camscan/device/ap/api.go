@@ -2,16 +2,20 @@ package ap
 
 import (
 	"as/camscan/internal/camscan/logging"
+	snmpApi "as/camscan/internal/camscan/snmp"
 	"as/camscan/internal/camscan/types/device"
+	snmpTypes "as/camscan/internal/camscan/types/snmp"
 	"as/camscan/internal/camscan/workers"
 	"context"
-	"github.com/gosnmp/gosnmp"
+	"errors"
+	"fmt"
 	"net"
 	"strings"
 	"time"
 )
 
-func ScanDevice(ctx context.Context, args interface{}, descriptor workers.JobDescriptor) (interface{}, error) {
+func ScanDevice(ctx context.Context, request snmpTypes.PollRequest,
+	descriptor workers.JobDescriptor) (map[string]interface{}, error) {
 	var record = descriptor.Metadata["record"].(device.AccessPoint)
 	results := make(map[string]interface{})
 	timeout := time.Duration(1000000000 * descriptor.AppConfig.SnmpTimeoutSm)
@@ -21,19 +25,27 @@ func ScanDevice(ctx context.Context, args interface{}, descriptor workers.JobDes
 		record.Id, record.NetworkId, record.MacAddress, record.IPv4Address, record.IPv4AddressInt, record.Status,
 		timeout)
 
-	snmp := &gosnmp.GoSNMP{
-		Target:    record.IPv4Address,
-		Port:      161,
-		Community: descriptor.AppConfig.SnmpApCommunity,
-		Version:   gosnmp.Version2c,
-		Timeout:   timeout,
-	}
+	credentials := snmpApi.GetCredentials(descriptor.AppConfig, record.NetworkId, snmpTypes.DeviceTypeAccessPoint,
+		record.SnmpCredential)
+	snmp, credential, snmpError := snmpApi.ConnectWithCredentials(ctx, record.IPv4Address, credentials, timeout)
 
-	snmpError := snmp.Connect()
+	// Remember which credential worked, or flag the device when none did, so later scans can use it directly
+	storeSnmpStatus(descriptor, record, credential, snmpError)
 
 	if snmpError != nil {
-		logging.Warning("Failed to open SNMP connection for access point; ip: %s;", record.IPv4Address)
-		return results, nil
+		if err := workers.CheckDeadline(ctx, descriptor, record.IPv4Address); err != nil {
+			return results, err
+		}
+
+		reason := snmpTypes.FailureAuth
+
+		if errors.Is(snmpError, snmpApi.ErrUnreachable) {
+			reason = snmpTypes.FailureUnreachable
+		}
+
+		logging.Debug("Failed to open SNMP connection for access point; ip: %s; error: %s;",
+			record.IPv4Address, snmpError.Error())
+		return results, &snmpApi.PollError{Reason: reason, Err: snmpError}
 	}
 
 	defer func(Conn net.Conn) {
@@ -46,23 +58,80 @@ func ScanDevice(ctx context.Context, args interface{}, descriptor workers.JobDes
 	oids := make([]string, 0)
 	oidMap := make(map[string]string)
 
-	for key, oid := range descriptor.Metadata["oids"].(map[string]string) {
+	// Counts the OIDs and tables that couldn't be retrieved, other than those the device doesn't provide
+	failed := 0
+	var lastFailure error = nil
+
+	for key, oid := range request.Oids {
 		oids = append(oids, oid)
 		oidMap[oid] = key
 	}
 
+	// Retrieve every table from the OID map which are stored as rows keyed by index
+	for key, oid := range request.Tables {
+		if ctx.Err() != nil {
+			break
+		}
+
+		logging.Trace1("Walking SNMP table for access point; ip: %s; oid: %s;", record.IPv4Address, oid)
+
+		table, snmpError := snmpApi.WalkTable(snmp, oid)
+
+		if snmpError != nil && ctx.Err() != nil {
+			break
+		}
+
+		if snmpError != nil {
+			logging.Warning("Failed to walk SNMP table for access point; ip: %s; oid: %s; error: %s;",
+				record.IPv4Address, oid, snmpError.Error())
+			failed++
+			lastFailure = snmpError
+			continue
+		}
+
+		// Values without a transform are left unchanged by the empty transform
+		request.Transforms[key].ApplyTable(table)
+		results[key] = table
+		logging.Trace2("Loaded table value; ip: %s; oid: %s; rows: %v;", record.IPv4Address, oid, len(table))
+	}
+
+	if err := workers.CheckDeadline(ctx, descriptor, record.IPv4Address); err != nil || len(oids) == 0 {
+		return results, err
+	}
+
 	logging.Trace1("Querying SNMP service for access point; ip: %s;", record.IPv4Address)
 
-	snmpResult, snmpError := snmp.Get(oids)
+	// Split the query into as many requests as the device needs while keeping the values that could be retrieved
+	snmp.MaxOids = descriptor.AppConfig.SnmpMaxOids
+	variables, failures := snmpApi.GetAll(snmp, oids)
+	deadlineError := workers.CheckDeadline(ctx, descriptor, record.IPv4Address)
 
-	if snmpError != nil {
-		logging.Warning("Failed to query SNMP service for access point; ip: %s;", record.IPv4Address)
-		return results, nil
+	// OIDs that couldn't be queried because the job ran out of time are reported through the job's error instead
+	if deadlineError != nil {
+		failures = nil
 	}
 
-	for _, variable := range snmpResult.Variables {
+	for oid, err := range failures {
+		if snmpApi.IsMissing(err) {
+			logging.Debug("OID is not available on access point; ip: %s; key: %s; oid: %s; error: %s;",
+				record.IPv4Address, oidMap[oid], oid, err.Error())
+			continue
+		}
+
+		logging.Warning("Failed to query OID for access point; ip: %s; key: %s; oid: %s; error: %s;",
+			record.IPv4Address, oidMap[oid], oid, err.Error())
+		failed++
+		lastFailure = err
+	}
+
+	if len(variables) == 0 && failed > 0 && len(results) == 0 {
+		logging.Debug("Failed to query SNMP service for access point; ip: %s;", record.IPv4Address)
+		return results, &snmpApi.PollError{Reason: snmpTypes.FailureTimeout, Err: lastFailure}
+	}
+
+	for _, variable := range variables {
 		// Cache a reference to the OID without the leading "."
-		oid := variable.Name[1:]
+		oid := strings.TrimPrefix(variable.Name, ".")
 
 		key, ok := oidMap[oid]
 
@@ -71,26 +140,62 @@ func ScanDevice(ctx context.Context, args interface{}, descriptor workers.JobDes
 			continue
 		}
 
-		// Process OID value based on type
-		if variable.Type == gosnmp.OctetString {
-			value := strings.Trim(string(variable.Value.([]byte)), " ")
-			results[key] = value
-
-			logging.Trace2("Loaded string value; ip: %s; oid: %s; value: %s;", record.IPv4Address, oid, value)
-		} else if variable.Type == gosnmp.Counter32 || variable.Type == gosnmp.Counter64 {
-			value := variable.Value
-			results[key] = value
-
-			logging.Trace2("Loaded counter value; ip: %s; oid: %s; value: %v;", record.IPv4Address, oid, value)
-		} else if variable.Type == gosnmp.Null {
-			logging.Trace1("Received SNMP value is nil for access point; ip: %s; oid: %s;",
-				record.IPv4Address, oid)
-		} else {
-			logging.Error("SNMP Exception - Unexpected value type for oid; ip: %s; oid: %s; type: %s;",
+		value, ok := snmpApi.Decode(variable)
+
+		if !ok {
+			logging.Trace1("Received SNMP value is empty for access point; ip: %s; oid: %s; type: %s;",
 				record.IPv4Address, oid, variable.Type)
-			logging.Trace(variable.Value.(string))
+			continue
 		}
+
+		value = request.Transforms[key].Apply(value)
+		results[key] = value
+
+		logging.Trace2("Loaded value; ip: %s; oid: %s; type: %s; value: %s;", record.IPv4Address, oid,
+			variable.Type, value)
+	}
+
+	if deadlineError == nil && failed > 0 {
+		return results, &snmpApi.PollError{
+			Reason: snmpTypes.FailurePartial,
+			Err:    fmt.Errorf("%v of %v OIDs couldn't be retrieved; %w", failed, len(oids)+len(request.Tables), lastFailure),
+		}
+	}
+
+	return results, deadlineError
+}
+
+func storeSnmpStatus(descriptor workers.JobDescriptor, record device.AccessPoint, credential snmpTypes.Credential,
+	snmpError error) {
+	status := device.SnmpStatusOk
+
+	// A device that didn't answer at all says nothing about its credentials, e.g. while it's rebooting
+	if errors.Is(snmpError, snmpApi.ErrUnreachable) {
+		return
+	}
+
+	if errors.Is(snmpError, snmpApi.ErrNoCredential) {
+		status = device.SnmpStatusNoCredential
+		credential.Name = record.SnmpCredential
+	} else if snmpError != nil {
+		return
 	}
 
-	return results, nil
+	if record.SnmpCredential == credential.Name && record.SnmpStatus == status {
+		return
+	}
+
+	if status == device.SnmpStatusNoCredential {
+		logging.Warning("No SNMP credential was accepted by access point; id: %v; ip: %s;", record.Id, record.IPv4Address)
+	} else {
+		logging.Debug("SNMP credential changed for access point; id: %v; ip: %s; credential: %s;",
+			record.Id, record.IPv4Address, credential.Name)
+	}
+
+	record.SnmpCredential = credential.Name
+	record.SnmpStatus = status
+
+	if descriptor.AppConfig.DryRun == false && descriptor.Storage != nil {
+		descriptor.Storage.AccessPoints.UpdateSnmpStatus(record)
+	}
 }
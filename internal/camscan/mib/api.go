@@ -0,0 +1,406 @@
+package mib
+
+import (
+	"as/camscan/internal/camscan/logging"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// roots defines the OIDs of the SMI's well-known nodes so that MIBs can refer to them without SNMPv2-SMI, or the SMIv1
+// RFC1155-SMI, being loaded
+var roots = map[string]string{
+	"ccitt":           "0",
+	"iso":             "1",
+	"joint-iso-ccitt": "2",
+	"org":             "1.3",
+	"dod":             "1.3.6",
+	"internet":        "1.3.6.1",
+	"directory":       "1.3.6.1.1",
+	"mgmt":            "1.3.6.1.2",
+	"mib-2":           "1.3.6.1.2.1",
+	"transmission":    "1.3.6.1.2.1.10",
+	"experimental":    "1.3.6.1.3",
+	"private":         "1.3.6.1.4",
+	"enterprises":     "1.3.6.1.4.1",
+	"security":        "1.3.6.1.5",
+	"snmpV2":          "1.3.6.1.6",
+	"snmpDomains":     "1.3.6.1.6.1",
+	"snmpProxys":      "1.3.6.1.6.2",
+	"snmpModules":     "1.3.6.1.6.3",
+	"zeroDotZero":     "0.0",
+}
+
+// Object is a named OID defined by a MIB module, e.g. an OBJECT-TYPE. Enums holds the labels of enumerated integer
+// values by number, either defined by the object's syntax or by the textual convention it refers to.
+type Object struct {
+	Module string
+	Name   string
+	Kind   string
+	Oid    string
+	Syntax string
+	Enums  map[int]string
+}
+
+// Tree holds the objects defined by a set of MIB modules, indexed by name and OID
+type Tree struct {
+	modules map[string]*module
+	names   map[string][]*Object
+	oids    map[string]*Object
+}
+
+// Load parses every MIB file in the given directory and resolves the OIDs of the objects they define. Modules may
+// import from any other module in the directory, in any order. An empty directory path loads no modules, in which
+// case only numeric OIDs can be resolved.
+func Load(dir string) (*Tree, error) {
+	tree := &Tree{
+		modules: make(map[string]*module),
+		names:   make(map[string][]*Object),
+		oids:    make(map[string]*Object),
+	}
+
+	if dir == "" {
+		return tree, nil
+	}
+
+	entries, err := os.ReadDir(dir)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to read MIB directory '%s'; %w", dir, err)
+	}
+
+	for _, entry := range entries {
+		if entry.IsDir() || strings.HasPrefix(entry.Name(), ".") {
+			continue
+		}
+
+		path := filepath.Join(dir, entry.Name())
+		contents, err := os.ReadFile(path)
+
+		if err != nil {
+			return nil, fmt.Errorf("failed to read MIB file '%s'; %w", path, err)
+		}
+
+		tokens, err := tokenize(string(contents))
+
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+
+		p := &parser{file: path, tokens: tokens}
+		modules, err := p.parseModules()
+
+		if err != nil {
+			return nil, err
+		}
+
+		if len(modules) == 0 {
+			logging.Debug("Skipping file without MIB modules; path: %s;", path)
+			continue
+		}
+
+		for _, m := range modules {
+			if existing, ok := tree.modules[m.name]; ok {
+				return nil, fmt.Errorf("MIB module %s is defined by both '%s' and '%s'", m.name, existing.file, path)
+			}
+
+			tree.modules[m.name] = m
+		}
+	}
+
+	if err = tree.resolve(); err != nil {
+		return nil, err
+	}
+
+	logging.Info("Loaded %v MIB modules with %v objects; path: %s;", len(tree.modules), len(tree.oids), dir)
+
+	return tree, nil
+}
+
+// Modules returns the names of the loaded modules in alphabetical order
+func (t *Tree) Modules() []string {
+	names := make([]string, 0, len(t.modules))
+
+	for name := range t.modules {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	return names
+}
+
+// Resolve converts a symbolic name like "WHISP-SM-MIB::jitter.0", or an unqualified one like "jitter.0", into its
+// numeric OID. The instance suffix that follows the name is kept. Numeric OIDs are validated and returned without a
+// leading dot. Unqualified names must be unique across the loaded modules.
+func (t *Tree) Resolve(name string) (string, error) {
+	name = strings.TrimSpace(name)
+
+	if isNumericOid(strings.TrimPrefix(name, ".")) {
+		return strings.TrimPrefix(name, "."), nil
+	}
+
+	moduleName := ""
+	symbol := name
+
+	if index := strings.Index(name, "::"); index >= 0 {
+		moduleName = name[:index]
+		symbol = name[index+2:]
+	}
+
+	suffix := ""
+
+	if index := strings.Index(symbol, "."); index >= 0 {
+		suffix = symbol[index+1:]
+		symbol = symbol[:index]
+
+		if !isNumericOid(suffix) {
+			return "", fmt.Errorf("invalid instance suffix '%s' of '%s'", suffix, name)
+		}
+	}
+
+	if symbol == "" {
+		return "", fmt.Errorf("invalid OID '%s'", name)
+	}
+
+	var object *Object
+
+	if moduleName != "" {
+		if _, ok := t.modules[moduleName]; !ok {
+			return "", fmt.Errorf("MIB module %s of '%s' isn't loaded", moduleName, name)
+		}
+
+		for _, candidate := range t.names[symbol] {
+			if candidate.Module == moduleName {
+				object = candidate
+			}
+		}
+
+		if object == nil {
+			return "", fmt.Errorf("MIB module %s doesn't define '%s'", moduleName, symbol)
+		}
+	} else {
+		candidates := t.names[symbol]
+
+		if len(candidates) == 0 {
+			if oid, ok := roots[symbol]; ok {
+				return joinOid(oid, suffix), nil
+			}
+
+			return "", fmt.Errorf("no loaded MIB module defines '%s'", symbol)
+		}
+
+		for _, candidate := range candidates[1:] {
+			if candidate.Oid != candidates[0].Oid {
+				return "", fmt.Errorf("'%s' is defined by several MIB modules; qualify it like %s::%s",
+					symbol, candidates[0].Module, name)
+			}
+		}
+
+		object = candidates[0]
+	}
+
+	return joinOid(object.Oid, suffix), nil
+}
+
+// Lookup finds the object that the given OID belongs to, i.e. the object with the longest OID that the given OID
+// starts with, returning the object along with the instance suffix that follows its OID, e.g. "0" for scalars
+func (t *Tree) Lookup(oid string) (*Object, string, bool) {
+	oid = strings.TrimPrefix(oid, ".")
+	prefix := oid
+
+	for prefix != "" {
+		if object, ok := t.oids[prefix]; ok {
+			return object, strings.TrimPrefix(strings.TrimPrefix(oid, prefix), "."), true
+		}
+
+		index := strings.LastIndex(prefix, ".")
+
+		if index < 0 {
+			break
+		}
+
+		prefix = prefix[:index]
+	}
+
+	return nil, "", false
+}
+
+// Label returns the label that the MIB object of the given OID defines for an enumerated integer value
+func (t *Tree) Label(oid string, value int) (string, bool) {
+	object, _, ok := t.Lookup(oid)
+
+	if !ok || object.Enums == nil {
+		return "", false
+	}
+
+	label, ok := object.Enums[value]
+
+	return label, ok
+}
+
+// Name returns the symbolic name of the given OID like "WHISP-SM-MIB::jitter.0", or the numeric OID when no loaded
+// module defines it
+func (t *Tree) Name(oid string) string {
+	object, suffix, ok := t.Lookup(oid)
+
+	if !ok {
+		return strings.TrimPrefix(oid, ".")
+	}
+
+	return object.Module + "::" + joinOid(object.Name, suffix)
+}
+
+// resolve computes the OID of every node of every module and indexes the resulting objects
+func (t *Tree) resolve() error {
+	for _, moduleName := range t.Modules() {
+		m := t.modules[moduleName]
+
+		for _, name := range m.order {
+			n := m.nodes[name]
+
+			if _, err := t.resolveNode(n); err != nil {
+				return err
+			}
+
+			object := &Object{
+				Module: m.name,
+				Name:   n.name,
+				Kind:   n.kind,
+				Oid:    n.oid,
+				Syntax: n.syntax.name,
+				Enums:  t.resolveEnums(m, n.syntax, 0),
+			}
+
+			t.names[n.name] = append(t.names[n.name], object)
+
+			// The first module to define an OID names it, in alphabetical order of module names
+			if _, ok := t.oids[n.oid]; !ok {
+				t.oids[n.oid] = object
+			}
+		}
+	}
+
+	return nil
+}
+
+func (t *Tree) resolveNode(n *node) (string, error) {
+	if n.oid != "" {
+		return n.oid, nil
+	}
+
+	if n.resolving {
+		return "", fmt.Errorf("%s::%s is defined in terms of itself", n.module, n.name)
+	}
+
+	n.resolving = true
+	defer func() { n.resolving = false }()
+
+	oid := ""
+
+	if n.parent != "" {
+		parent, err := t.resolveParent(n.module, n.parent)
+
+		if err != nil {
+			return "", fmt.Errorf("%s::%s, line %v: %w", n.module, n.name, n.line, err)
+		}
+
+		oid = parent
+	}
+
+	for _, arc := range n.arcs {
+		oid = joinOid(oid, strconv.Itoa(arc))
+	}
+
+	n.oid = oid
+
+	return oid, nil
+}
+
+// resolveParent finds the OID of a name that a node of the given module refers to. The name is looked up in the module
+// itself, then in the module it's imported from, then among the well-known nodes and finally in any loaded module.
+func (t *Tree) resolveParent(moduleName string, name string) (string, error) {
+	m := t.modules[moduleName]
+
+	if n, ok := m.nodes[name]; ok {
+		return t.resolveNode(n)
+	}
+
+	if source, ok := t.modules[m.imports[name]]; ok {
+		if n, ok := source.nodes[name]; ok {
+			return t.resolveNode(n)
+		}
+	}
+
+	if oid, ok := roots[name]; ok {
+		return oid, nil
+	}
+
+	for _, otherName := range t.Modules() {
+		if n, ok := t.modules[otherName].nodes[name]; ok {
+			return t.resolveNode(n)
+		}
+	}
+
+	if source := m.imports[name]; source != "" {
+		return "", fmt.Errorf("unknown parent '%s'; is the %s MIB missing?", name, source)
+	}
+
+	return "", fmt.Errorf("unknown parent '%s'", name)
+}
+
+// resolveEnums returns the enums of the given syntax or, for a syntax that refers to a textual convention, the enums of
+// the textual convention
+func (t *Tree) resolveEnums(m *module, s syntax, depth int) map[int]string {
+	if s.enums != nil || depth > 8 {
+		return s.enums
+	}
+
+	if tc, ok := m.types[s.name]; ok {
+		return t.resolveEnums(m, tc, depth+1)
+	}
+
+	if source, ok := t.modules[m.imports[s.name]]; ok {
+		if tc, ok := source.types[s.name]; ok {
+			return t.resolveEnums(source, tc, depth+1)
+		}
+	}
+
+	return nil
+}
+
+func joinOid(oid string, suffix string) string {
+	if oid == "" {
+		return suffix
+	}
+
+	if suffix == "" {
+		return oid
+	}
+
+	return oid + "." + suffix
+}
+
+// isNumericOid determines whether the given text is a dotted sequence of numbers like "1.3.6.1"
+func isNumericOid(text string) bool {
+	if text == "" {
+		return false
+	}
+
+	for _, arc := range strings.Split(text, ".") {
+		if arc == "" {
+			return false
+		}
+
+		for _, r := range arc {
+			if r < '0' || r > '9' {
+				return false
+			}
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,87 @@
+package mib
+
+import (
+	"fmt"
+	"unicode"
+)
+
+// token is a single lexical element of a MIB module, e.g. an identifier, a number, a quoted string or a symbol
+type token struct {
+	text string
+	line int
+}
+
+// tokenize splits the contents of a MIB file into tokens, dropping comments and whitespace. Quoted strings, which
+// hold descriptions, are kept as a single token including their quotes.
+func tokenize(contents string) ([]token, error) {
+	tokens := make([]token, 0, len(contents)/8)
+	runes := []rune(contents)
+	line := 1
+
+	for i := 0; i < len(runes); {
+		r := runes[i]
+
+		switch {
+		case r == '\n':
+			line++
+			i++
+		case unicode.IsSpace(r):
+			i++
+		case r == '-' && i+1 < len(runes) && runes[i+1] == '-':
+			// Comments end at the end of the line or at the next "--"
+			i += 2
+			for i < len(runes) && runes[i] != '\n' {
+				if runes[i] == '-' && i+1 < len(runes) && runes[i+1] == '-' {
+					i += 2
+					break
+				}
+				i++
+			}
+		case r == '"' || r == '\'':
+			start, startLine := i, line
+			i++
+			for i < len(runes) && runes[i] != r {
+				if runes[i] == '\n' {
+					line++
+				}
+				i++
+			}
+			if i >= len(runes) {
+				return nil, fmt.Errorf("unterminated string starting on line %v", startLine)
+			}
+			i++
+			// Hex and binary strings like '00'H are followed by their radix
+			if r == '\'' && i < len(runes) && (runes[i] == 'H' || runes[i] == 'h' || runes[i] == 'B' || runes[i] == 'b') {
+				i++
+			}
+			tokens = append(tokens, token{text: string(runes[start:i]), line: startLine})
+		case r == ':' && i+2 < len(runes) && runes[i+1] == ':' && runes[i+2] == '=':
+			tokens = append(tokens, token{text: "::=", line: line})
+			i += 3
+		case r == '.' && i+1 < len(runes) && runes[i+1] == '.':
+			tokens = append(tokens, token{text: "..", line: line})
+			i += 2
+		case unicode.IsLetter(r) || unicode.IsDigit(r) || (r == '-' && i+1 < len(runes) && unicode.IsDigit(runes[i+1])):
+			start := i
+			i++
+			for i < len(runes) {
+				c := runes[i]
+				// A hyphen is part of an identifier unless it starts a comment
+				if c == '-' && !(i+1 < len(runes) && runes[i+1] == '-') {
+					i++
+					continue
+				}
+				if !unicode.IsLetter(c) && !unicode.IsDigit(c) && c != '_' {
+					break
+				}
+				i++
+			}
+			tokens = append(tokens, token{text: string(runes[start:i]), line: line})
+		default:
+			tokens = append(tokens, token{text: string(r), line: line})
+			i++
+		}
+	}
+
+	return tokens, nil
+}
@@ -0,0 +1,90 @@
+package mib
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestTokenize(t *testing.T) {
+	tests := []struct {
+		name     string
+		contents string
+		tokens   []string
+	}{
+		{
+			name:     "assignment",
+			contents: "testObjects OBJECT IDENTIFIER ::= { camscanTestMib 1 }",
+			tokens:   []string{"testObjects", "OBJECT", "IDENTIFIER", "::=", "{", "camscanTestMib", "1", "}"},
+		},
+		{
+			name:     "comment to the end of the line",
+			contents: "a -- b c\nd",
+			tokens:   []string{"a", "d"},
+		},
+		{
+			name:     "comment to the next pair of hyphens",
+			contents: "a -- b -- c",
+			tokens:   []string{"a", "c"},
+		},
+		{
+			name:     "hyphens within identifiers",
+			contents: "OBJECT-TYPE SNMPv2-SMI mib-2",
+			tokens:   []string{"OBJECT-TYPE", "SNMPv2-SMI", "mib-2"},
+		},
+		{
+			name:     "quoted string spanning lines",
+			contents: "DESCRIPTION \"a -- b\n c\" END",
+			tokens:   []string{"DESCRIPTION", "\"a -- b\n c\"", "END"},
+		},
+		{
+			name:     "hex and binary strings",
+			contents: "DEFVAL { '00FF'H } DEFVAL { '0101'b }",
+			tokens:   []string{"DEFVAL", "{", "'00FF'H", "}", "DEFVAL", "{", "'0101'b", "}"},
+		},
+		{
+			name:     "ranges and negative numbers",
+			contents: "INTEGER (-1..10)",
+			tokens:   []string{"INTEGER", "(", "-1", "..", "10", ")"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			tokens, err := tokenize(test.contents)
+
+			if err != nil {
+				t.Fatalf("error = %v", err)
+			}
+
+			texts := make([]string, 0, len(tokens))
+
+			for _, token := range tokens {
+				texts = append(texts, token.text)
+			}
+
+			if !reflect.DeepEqual(texts, test.tokens) {
+				t.Errorf("tokens = %q, want %q", texts, test.tokens)
+			}
+		})
+	}
+}
+
+func TestTokenizeLines(t *testing.T) {
+	tokens, err := tokenize("a\n\"b\nc\"\n-- d\ne")
+
+	if err != nil {
+		t.Fatalf("error = %v", err)
+	}
+
+	want := []token{{text: "a", line: 1}, {text: "\"b\nc\"", line: 2}, {text: "e", line: 5}}
+
+	if !reflect.DeepEqual(tokens, want) {
+		t.Errorf("tokens = %v, want %v", tokens, want)
+	}
+}
+
+func TestTokenizeUnterminatedString(t *testing.T) {
+	if _, err := tokenize("DESCRIPTION \"never closed\nEND"); err == nil {
+		t.Error("error = nil, want an unterminated string error")
+	}
+}
@@ -0,0 +1,425 @@
+package mib
+
+import (
+	"fmt"
+	"strconv"
+	"unicode"
+)
+
+// macros lists the SMI macros whose invocations assign an OID to the name that precedes them
+var macros = map[string]bool{
+	"MODULE-IDENTITY":    true,
+	"OBJECT-IDENTITY":    true,
+	"OBJECT-TYPE":        true,
+	"NOTIFICATION-TYPE":  true,
+	"OBJECT-GROUP":       true,
+	"NOTIFICATION-GROUP": true,
+	"MODULE-COMPLIANCE":  true,
+	"AGENT-CAPABILITIES": true,
+	"TRAP-TYPE":          true,
+}
+
+// module is a single parsed MIB module
+type module struct {
+	name    string
+	file    string
+	imports map[string]string
+	nodes   map[string]*node
+	order   []string
+	types   map[string]syntax
+}
+
+// node is an OID assignment whose OID is resolved once every module is parsed. The OID is the OID of the named parent
+// followed by the given arcs, or only the arcs when there's no parent.
+type node struct {
+	module    string
+	name      string
+	kind      string
+	line      int
+	parent    string
+	arcs      []int
+	syntax    syntax
+	oid       string
+	resolving bool
+}
+
+// syntax is the type of an object, where enums holds the labels of enumerated integers by number
+type syntax struct {
+	name  string
+	enums map[int]string
+}
+
+// parser reads the modules defined by the tokens of a single MIB file
+type parser struct {
+	file   string
+	tokens []token
+	pos    int
+}
+
+func (p *parser) peek(offset int) string {
+	if p.pos+offset < len(p.tokens) {
+		return p.tokens[p.pos+offset].text
+	}
+
+	return ""
+}
+
+func (p *parser) line() int {
+	if p.pos < len(p.tokens) {
+		return p.tokens[p.pos].line
+	}
+
+	if len(p.tokens) > 0 {
+		return p.tokens[len(p.tokens)-1].line
+	}
+
+	return 0
+}
+
+func (p *parser) errorf(format string, args ...interface{}) error {
+	return fmt.Errorf("%s:%v: %s", p.file, p.line(), fmt.Sprintf(format, args...))
+}
+
+// parseModules parses every module defined in the file
+func (p *parser) parseModules() ([]*module, error) {
+	modules := make([]*module, 0, 1)
+
+	for p.pos < len(p.tokens) {
+		// Modules start with "NAME DEFINITIONS ::= BEGIN"
+		if p.peek(1) != "DEFINITIONS" {
+			p.pos++
+			continue
+		}
+
+		m := &module{
+			name:    p.peek(0),
+			file:    p.file,
+			imports: make(map[string]string),
+			nodes:   make(map[string]*node),
+			types:   make(map[string]syntax),
+		}
+
+		for p.pos < len(p.tokens) && p.peek(0) != "BEGIN" {
+			p.pos++
+		}
+
+		p.pos++
+
+		if err := p.parseBody(m); err != nil {
+			return nil, err
+		}
+
+		modules = append(modules, m)
+	}
+
+	return modules, nil
+}
+
+// parseBody parses the assignments of a module up to its closing END
+func (p *parser) parseBody(m *module) error {
+	for p.pos < len(p.tokens) {
+		current := p.peek(0)
+		next := p.peek(1)
+
+		switch {
+		case current == "END":
+			p.pos++
+			return nil
+		case current == "IMPORTS":
+			p.pos++
+			p.parseImports(m)
+		case next == "MACRO":
+			// Macro definitions like those of SNMPv2-SMI describe the notation rather than any OID
+			if err := p.skipTo("END"); err != nil {
+				return err
+			}
+			p.pos++
+		case next == "OBJECT" && p.peek(2) == "IDENTIFIER" && p.peek(3) == "::=" && isValueName(current):
+			n := &node{module: m.name, name: current, kind: "OBJECT IDENTIFIER", line: p.line()}
+			p.pos += 4
+			if err := p.parseOidValue(n); err != nil {
+				return err
+			}
+			m.addNode(n)
+		case macros[next] && isValueName(current):
+			n, err := p.parseMacro(m.name)
+			if err != nil {
+				return err
+			}
+			if n != nil {
+				m.addNode(n)
+			}
+		case next == "::=" && isTypeName(current):
+			p.pos += 2
+			if s, ok := p.parseTypeAssignment(); ok {
+				m.types[current] = s
+			}
+		default:
+			p.pos++
+		}
+	}
+
+	return p.errorf("module %s isn't terminated by END", m.name)
+}
+
+// parseImports reads the symbols imported from each module, e.g. "ifIndex FROM IF-MIB", up to the closing semicolon
+func (p *parser) parseImports(m *module) {
+	symbols := make([]string, 0)
+
+	for p.pos < len(p.tokens) && p.peek(0) != ";" {
+		current := p.peek(0)
+		p.pos++
+
+		switch current {
+		case ",":
+		case "FROM":
+			for _, symbol := range symbols {
+				m.imports[symbol] = p.peek(0)
+			}
+			symbols = symbols[:0]
+			p.pos++
+		default:
+			symbols = append(symbols, current)
+		}
+	}
+
+	p.pos++
+}
+
+// parseMacro parses a macro invocation like OBJECT-TYPE up to and including its OID value. Only the SYNTAX clause of
+// the invocation is kept, along with the ENTERPRISE clause of SMIv1 traps.
+func (p *parser) parseMacro(moduleName string) (*node, error) {
+	n := &node{module: moduleName, name: p.peek(0), kind: p.peek(1), line: p.line()}
+	enterprise := ""
+	p.pos += 2
+
+	for p.pos < len(p.tokens) && p.peek(0) != "::=" {
+		switch p.peek(0) {
+		case "SYNTAX":
+			p.pos++
+			n.syntax = p.parseSyntax()
+		case "ENTERPRISE":
+			enterprise = p.peek(1)
+			p.pos += 2
+		case "{", "(":
+			p.skipGroup()
+		case "END":
+			return nil, p.errorf("missing value of %s", n.name)
+		default:
+			p.pos++
+		}
+	}
+
+	p.pos++
+
+	// SMIv1 traps are numbered within their enterprise, which SNMPv2 maps to the OID enterprise.0.number
+	if n.kind == "TRAP-TYPE" {
+		number, err := strconv.Atoi(p.peek(0))
+
+		if err != nil || enterprise == "" {
+			return nil, p.errorf("invalid trap number or enterprise of %s", n.name)
+		}
+
+		n.parent = enterprise
+		n.arcs = []int{0, number}
+		p.pos++
+
+		return n, nil
+	}
+
+	if err := p.parseOidValue(n); err != nil {
+		return nil, err
+	}
+
+	return n, nil
+}
+
+// parseOidValue parses an OID value like "{ enterprises 161 }" or "{ iso(1) org(3) 6 }". The first component may name
+// the parent, while the other components must be numbers, optionally labelled.
+func (p *parser) parseOidValue(n *node) error {
+	if p.peek(0) != "{" {
+		return p.errorf("expected OID value of %s", n.name)
+	}
+
+	p.pos++
+
+	for first := true; p.pos < len(p.tokens) && p.peek(0) != "}"; first = false {
+		component := p.peek(0)
+		p.pos++
+
+		if number, err := strconv.Atoi(component); err == nil {
+			n.arcs = append(n.arcs, number)
+			continue
+		}
+
+		// A labelled number like "org(3)"
+		if p.peek(0) == "(" {
+			number, err := strconv.Atoi(p.peek(1))
+
+			if err != nil || p.peek(2) != ")" {
+				return p.errorf("invalid OID component %s of %s", component, n.name)
+			}
+
+			n.arcs = append(n.arcs, number)
+			p.pos += 3
+			continue
+		}
+
+		if !first {
+			return p.errorf("unexpected OID component %s of %s", component, n.name)
+		}
+
+		n.parent = component
+	}
+
+	p.pos++
+
+	if n.parent == "" && len(n.arcs) == 0 {
+		return p.errorf("empty OID value of %s", n.name)
+	}
+
+	return nil
+}
+
+// parseTypeAssignment parses the type of a type assignment like "Status ::= INTEGER { up(1), down(2) }" or a textual
+// convention, returning false when the type can't be used as the syntax of an object
+func (p *parser) parseTypeAssignment() (syntax, bool) {
+	if p.peek(0) == "TEXTUAL-CONVENTION" {
+		for p.pos < len(p.tokens) && p.peek(0) != "SYNTAX" {
+			if p.peek(0) == "END" || p.peek(0) == "::=" {
+				return syntax{}, false
+			}
+			p.pos++
+		}
+
+		p.pos++
+	}
+
+	// Application types like "[APPLICATION 4] IMPLICIT OCTET STRING" are tagged
+	if p.peek(0) == "[" {
+		for p.pos < len(p.tokens) && p.peek(0) != "]" {
+			p.pos++
+		}
+		p.pos++
+	}
+
+	if p.peek(0) == "IMPLICIT" || p.peek(0) == "EXPLICIT" {
+		p.pos++
+	}
+
+	s := p.parseSyntax()
+
+	return s, s.name != "SEQUENCE" && s.name != "CHOICE"
+}
+
+// parseSyntax parses a type like "INTEGER { up(1), down(2) }", "OCTET STRING (SIZE (0..255))" or "SEQUENCE OF IfEntry"
+func (p *parser) parseSyntax() syntax {
+	s := syntax{name: p.peek(0)}
+	p.pos++
+
+	switch s.name {
+	case "OCTET", "OBJECT":
+		s.name += " " + p.peek(0)
+		p.pos++
+	case "SEQUENCE":
+		if p.peek(0) == "OF" {
+			s.name += " OF " + p.peek(1)
+			p.pos += 2
+			return s
+		}
+	}
+
+	if p.peek(0) == "{" {
+		if s.name == "BITS" || s.name == "SEQUENCE" || s.name == "CHOICE" {
+			p.skipGroup()
+		} else {
+			s.enums = p.parseEnums()
+		}
+	}
+
+	if p.peek(0) == "(" {
+		p.skipGroup()
+	}
+
+	return s
+}
+
+// parseEnums parses enumerated values like "{ up(1), down(2) }"
+func (p *parser) parseEnums() map[int]string {
+	enums := make(map[int]string)
+	p.pos++
+
+	for p.pos < len(p.tokens) && p.peek(0) != "}" {
+		if p.peek(1) == "(" && p.peek(3) == ")" {
+			if number, err := strconv.Atoi(p.peek(2)); err == nil {
+				enums[number] = p.peek(0)
+			}
+			p.pos += 4
+			continue
+		}
+
+		p.pos++
+	}
+
+	p.pos++
+
+	return enums
+}
+
+// skipGroup skips a group of tokens enclosed in braces or parentheses, including any nested groups
+func (p *parser) skipGroup() {
+	depth := 0
+
+	for p.pos < len(p.tokens) {
+		switch p.peek(0) {
+		case "{", "(":
+			depth++
+		case "}", ")":
+			depth--
+		}
+
+		p.pos++
+
+		if depth == 0 {
+			return
+		}
+	}
+}
+
+// skipTo advances to the next token with the given text
+func (p *parser) skipTo(text string) error {
+	for p.pos < len(p.tokens) {
+		if p.peek(0) == text {
+			return nil
+		}
+		p.pos++
+	}
+
+	return p.errorf("expected %s", text)
+}
+
+func (m *module) addNode(n *node) {
+	if _, ok := m.nodes[n.name]; !ok {
+		m.order = append(m.order, n.name)
+	}
+
+	m.nodes[n.name] = n
+}
+
+// isValueName determines whether the identifier names a value, which starts with a lowercase letter
+func isValueName(name string) bool {
+	for _, r := range name {
+		return unicode.IsLower(r)
+	}
+
+	return false
+}
+
+// isTypeName determines whether the identifier names a type, which starts with an uppercase letter
+func isTypeName(name string) bool {
+	for _, r := range name {
+		return unicode.IsUpper(r)
+	}
+
+	return false
+}
@@ -0,0 +1,300 @@
+package mib
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+const testMibDir = "testdata/mibs"
+
+// parseString parses and resolves the modules of a single MIB file's contents the way Load does
+func parseString(contents string) (*Tree, error) {
+	tokens, err := tokenize(contents)
+
+	if err != nil {
+		return nil, err
+	}
+
+	p := &parser{file: "TEST.txt", tokens: tokens}
+	modules, err := p.parseModules()
+
+	if err != nil {
+		return nil, err
+	}
+
+	tree := &Tree{
+		modules: make(map[string]*module),
+		names:   make(map[string][]*Object),
+		oids:    make(map[string]*Object),
+	}
+
+	for _, m := range modules {
+		tree.modules[m.name] = m
+	}
+
+	return tree, tree.resolve()
+}
+
+func TestParseImports(t *testing.T) {
+	contents, err := os.ReadFile(filepath.Join(testMibDir, "CAMSCAN-TEST-MIB.txt"))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tree, err := parseString(string(contents))
+
+	if err != nil {
+		t.Fatalf("error = %v", err)
+	}
+
+	want := map[string]string{
+		"MODULE-IDENTITY":   "SNMPv2-SMI",
+		"OBJECT-TYPE":       "SNMPv2-SMI",
+		"NOTIFICATION-TYPE": "SNMPv2-SMI",
+		"Integer32":         "SNMPv2-SMI",
+		"enterprises":       "SNMPv2-SMI",
+		"TestStatus":        "CAMSCAN-TEST-TC-MIB",
+	}
+
+	if imports := tree.modules["CAMSCAN-TEST-MIB"].imports; !reflect.DeepEqual(imports, want) {
+		t.Errorf("imports = %v, want %v", imports, want)
+	}
+}
+
+func TestLoad(t *testing.T) {
+	tree, err := Load(testMibDir)
+
+	if err != nil {
+		t.Fatalf("error = %v", err)
+	}
+
+	if modules := tree.Modules(); !reflect.DeepEqual(modules, []string{"CAMSCAN-TEST-MIB", "CAMSCAN-TEST-TC-MIB"}) {
+		t.Errorf("modules = %v", modules)
+	}
+
+	resolved := map[string]string{
+		"camscanTestMib":                   "1.3.6.1.4.1.99999",
+		"testObjects":                      "1.3.6.1.4.1.99999.1",
+		"testLabelled":                     "1.3.6.1.4.1.99999.3",
+		"testInline":                       "1.3.6.1.4.1.99999.1.8",
+		"testMode.0":                       "1.3.6.1.4.1.99999.1.1.0",
+		"CAMSCAN-TEST-MIB::testStatus.0":   "1.3.6.1.4.1.99999.1.2.0",
+		"testName.7":                       "1.3.6.1.4.1.99999.1.4.1.2.7",
+		"testModeChanged":                  "1.3.6.1.4.1.99999.2.1",
+		"enterprises":                      "1.3.6.1.4.1",
+		".1.3.6.1.4.1.99999.1.3.0":         "1.3.6.1.4.1.99999.1.3.0",
+		"CAMSCAN-TEST-MIB::testTable":      "1.3.6.1.4.1.99999.1.4",
+		"CAMSCAN-TEST-MIB::testEntry.1.42": "1.3.6.1.4.1.99999.1.4.1.1.42",
+	}
+
+	for name, want := range resolved {
+		if oid, err := tree.Resolve(name); err != nil || oid != want {
+			t.Errorf("Resolve(%s) = %s, %v, want %s", name, oid, err, want)
+		}
+	}
+
+	for _, name := range []string{"testRemoved", "TestEntry", "CAMSCAN-TEST-TC-MIB::testMode", "testMode.x"} {
+		if oid, err := tree.Resolve(name); err == nil {
+			t.Errorf("Resolve(%s) = %s, want an error", name, oid)
+		}
+	}
+}
+
+func TestLoadObjectTypes(t *testing.T) {
+	tree, err := Load(testMibDir)
+
+	if err != nil {
+		t.Fatalf("error = %v", err)
+	}
+
+	tests := []struct {
+		oid    string
+		name   string
+		kind   string
+		syntax string
+		enums  map[int]string
+	}{
+		{
+			oid:    "1.3.6.1.4.1.99999.1.1.0",
+			name:   "CAMSCAN-TEST-MIB::testMode.0",
+			kind:   "OBJECT-TYPE",
+			syntax: "INTEGER",
+			enums:  map[int]string{0: "off", 1: "on", 2: "auto"},
+		},
+		{
+			oid:    "1.3.6.1.4.1.99999.1.2.0",
+			name:   "CAMSCAN-TEST-MIB::testStatus.0",
+			kind:   "OBJECT-TYPE",
+			syntax: "TestStatus",
+			enums:  map[int]string{1: "up", 2: "down", 3: "testing"},
+		},
+		{
+			oid:    "1.3.6.1.4.1.99999.1.3.0",
+			name:   "CAMSCAN-TEST-MIB::testCount.0",
+			kind:   "OBJECT-TYPE",
+			syntax: "Integer32",
+		},
+		{
+			oid:    "1.3.6.1.4.1.99999.1.4",
+			name:   "CAMSCAN-TEST-MIB::testTable",
+			kind:   "OBJECT-TYPE",
+			syntax: "SEQUENCE OF TestEntry",
+		},
+		{
+			oid:    "1.3.6.1.4.1.99999.1.4.1.2.7",
+			name:   "CAMSCAN-TEST-MIB::testName.7",
+			kind:   "OBJECT-TYPE",
+			syntax: "OCTET STRING",
+		},
+		{
+			oid:  "1.3.6.1.4.1.99999.2.1",
+			name: "CAMSCAN-TEST-MIB::testModeChanged",
+			kind: "NOTIFICATION-TYPE",
+		},
+		{
+			oid:  "1.3.6.1.4.1.99999.1",
+			name: "CAMSCAN-TEST-MIB::testObjects",
+			kind: "OBJECT IDENTIFIER",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			object, _, ok := tree.Lookup(test.oid)
+
+			if !ok {
+				t.Fatalf("Lookup(%s) found nothing", test.oid)
+			}
+
+			if object.Kind != test.kind || object.Syntax != test.syntax || !reflect.DeepEqual(object.Enums, test.enums) {
+				t.Errorf("object = %+v, want kind %s, syntax %s and enums %v", object, test.kind, test.syntax,
+					test.enums)
+			}
+
+			if name := tree.Name(test.oid); name != test.name {
+				t.Errorf("Name(%s) = %s, want %s", test.oid, name, test.name)
+			}
+		})
+	}
+
+	if label, ok := tree.Label("1.3.6.1.4.1.99999.1.1.0", 2); !ok || label != "auto" {
+		t.Errorf("label = %s, %v, want auto", label, ok)
+	}
+
+	if label, ok := tree.Label("1.3.6.1.4.1.99999.1.3.0", 2); ok {
+		t.Errorf("label = %s, want none", label)
+	}
+
+	if name := tree.Name("1.3.6.1.2.1.1.3.0"); name != "1.3.6.1.2.1.1.3.0" {
+		t.Errorf("Name of an unknown OID = %s", name)
+	}
+}
+
+func TestParseMalformed(t *testing.T) {
+	tests := []struct {
+		name     string
+		contents string
+	}{
+		{
+			name:     "unterminated string",
+			contents: "M DEFINITIONS ::= BEGIN a OBJECT-TYPE DESCRIPTION \"never closed ::= { enterprises 1 } END",
+		},
+		{
+			name:     "missing END",
+			contents: "M DEFINITIONS ::= BEGIN a OBJECT IDENTIFIER ::= { enterprises 1 }",
+		},
+		{
+			name:     "missing BEGIN",
+			contents: "M DEFINITIONS ::=",
+		},
+		{
+			name:     "object without value",
+			contents: "M DEFINITIONS ::= BEGIN a OBJECT-TYPE SYNTAX INTEGER STATUS current END",
+		},
+		{
+			name:     "value without braces",
+			contents: "M DEFINITIONS ::= BEGIN a OBJECT IDENTIFIER ::= enterprises 1 END",
+		},
+		{
+			name:     "name after the first component",
+			contents: "M DEFINITIONS ::= BEGIN a OBJECT IDENTIFIER ::= { enterprises b } END",
+		},
+		{
+			name:     "labelled component without number",
+			contents: "M DEFINITIONS ::= BEGIN a OBJECT IDENTIFIER ::= { iso(x) 1 } END",
+		},
+		{
+			name:     "empty value",
+			contents: "M DEFINITIONS ::= BEGIN a OBJECT IDENTIFIER ::= { } END",
+		},
+		{
+			name:     "unterminated value",
+			contents: "M DEFINITIONS ::= BEGIN a OBJECT IDENTIFIER ::= { enterprises 1",
+		},
+		{
+			name:     "value ending the module",
+			contents: "M DEFINITIONS ::= BEGIN a OBJECT IDENTIFIER ::= { enterprises 1 END",
+		},
+		{
+			name:     "unknown parent",
+			contents: "M DEFINITIONS ::= BEGIN a OBJECT IDENTIFIER ::= { nowhere 1 } END",
+		},
+		{
+			name:     "cyclic parents",
+			contents: "M DEFINITIONS ::= BEGIN a OBJECT IDENTIFIER ::= { b 1 } b OBJECT IDENTIFIER ::= { a 1 } END",
+		},
+		{
+			name:     "trap without enterprise",
+			contents: "M DEFINITIONS ::= BEGIN a TRAP-TYPE DESCRIPTION \"a\" ::= 1 END",
+		},
+		{
+			name:     "trap without number",
+			contents: "M DEFINITIONS ::= BEGIN a TRAP-TYPE ENTERPRISE enterprises ::= END",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if _, err := parseString(test.contents); err == nil {
+				t.Error("error = nil, want an error")
+			}
+		})
+	}
+}
+
+// TestParseTruncated parses every prefix of the test MIB files, none of which may panic
+func TestParseTruncated(t *testing.T) {
+	for _, name := range []string{"CAMSCAN-TEST-MIB.txt", "CAMSCAN-TEST-TC-MIB.txt"} {
+		contents, err := os.ReadFile(filepath.Join(testMibDir, name))
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for i := range contents {
+			_, _ = parseString(string(contents[:i]))
+		}
+	}
+}
+
+func TestLoadDuplicateModule(t *testing.T) {
+	dir := t.TempDir()
+	contents, err := os.ReadFile(filepath.Join(testMibDir, "CAMSCAN-TEST-TC-MIB.txt"))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, name := range []string{"A.txt", "B.txt"} {
+		if err = os.WriteFile(filepath.Join(dir, name), contents, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if _, err = Load(dir); err == nil {
+		t.Error("error = nil, want a duplicate module error")
+	}
+}
@@ -1,16 +1,17 @@
 package network
 
 import (
-	dbAp "as/camscan/internal/camscan/database/device/ap"
-	dbSm "as/camscan/internal/camscan/database/device/sm"
-	dbSubnet "as/camscan/internal/camscan/database/network/subnet"
+	"as/camscan/internal/camscan/database/repository"
 	"as/camscan/internal/camscan/logging"
+	snmpApi "as/camscan/internal/camscan/snmp"
 	"as/camscan/internal/camscan/types"
 	"as/camscan/internal/camscan/types/device"
 	"as/camscan/internal/camscan/types/network"
+	snmpTypes "as/camscan/internal/camscan/types/snmp"
 	"as/camscan/internal/camscan/workers"
 	"context"
-	"database/sql"
+	"encoding/hex"
+	"errors"
 	"fmt"
 	"github.com/apparentlymart/go-cidr/cidr"
 	"github.com/gosnmp/gosnmp"
@@ -23,8 +24,11 @@ import (
 )
 
 const FirmwareModeOid = "1.3.6.1.2.1.1.1.0"
+const MacAddressOid = "1.3.6.1.4.1.161.19.3.3.1.3.0"
+const InterfaceMacAddressOid = "1.3.6.1.2.1.2.2.1.6.1"
 
-func PingHost(appConfig types.AppConfig, host string) bool {
+// PingHost sends a single ICMP echo request to the given host, giving up early once the given context is done.
+func PingHost(ctx context.Context, appConfig types.AppConfig, host string) bool {
 	alive := false
 	pinger, err := probing.NewPinger(host)
 
@@ -36,7 +40,15 @@ func PingHost(appConfig types.AppConfig, host string) bool {
 	pinger.Timeout = time.Duration(1000000000 * appConfig.ICMPTimeout)
 	pinger.Size = 24
 
-	err = pinger.Run()
+	if workers.WaitForPacket(ctx) != nil {
+		return false
+	}
+
+	err = pinger.RunWithContext(ctx)
+
+	if err != nil && ctx.Err() != nil {
+		return false
+	}
 
 	if err != nil {
 		logging.Error("ICMP Test Failed; host: %s; error: %s;", host, err.Error())
@@ -62,22 +74,23 @@ func PingHost(appConfig types.AppConfig, host string) bool {
 	return alive
 }
 
-func QueryHost(appConfig types.AppConfig, host string, oid string) (bool, interface{}) {
+func QueryHost(ctx context.Context, appConfig types.AppConfig, networkId int, host string,
+	oids []string) (bool, snmpTypes.Credential, map[string]interface{}) {
 	timeout := time.Duration(1000000000 * appConfig.SnmpTimeoutSm)
 
-	snmp := &gosnmp.GoSNMP{
-		Target:    host,
-		Port:      161,
-		Community: appConfig.SnmpSmCommunity,
-		Version:   gosnmp.Version2c,
-		Timeout:   timeout,
-	}
-
-	snmpError := snmp.Connect()
+	// Try every known credential since the device type isn't known until the device has been queried
+	credentials := snmpApi.GetCredentials(appConfig, networkId, snmpTypes.DeviceTypeSubscriberModule, "")
+	snmp, credential, snmpError := snmpApi.ConnectWithCredentials(ctx, host, credentials, timeout)
 
 	if snmpError != nil {
-		logging.Warning("Failed to open SNMP connection for device; ip: %s;", host)
-		return false, nil
+		// Plenty of hosts answer pings without running an SNMP agent, unlike agents that reject every credential
+		if errors.Is(snmpError, snmpApi.ErrUnreachable) || ctx.Err() != nil {
+			logging.Trace1("Failed to open SNMP connection for device; ip: %s; error: %s;", host, snmpError.Error())
+		} else {
+			logging.Warning("SNMP agent rejected every credential; ip: %s; error: %s;", host, snmpError.Error())
+		}
+
+		return false, credential, nil
 	}
 
 	defer func(Conn net.Conn) {
@@ -87,129 +100,260 @@ func QueryHost(appConfig types.AppConfig, host string, oid string) (bool, interf
 		}
 	}(snmp.Conn)
 
-	oids := []string{oid}
-
-	logging.Trace1("Querying SNMP service for device; ip: %s;", host)
+	logging.Trace1("Querying SNMP service for device; ip: %s; credential: %s;", host, credential.Name)
 
 	snmpResult, snmpError := snmp.Get(oids)
 
 	if snmpError != nil {
-		logging.Trace1("Failed to query SNMP service for device (sm); ip: %s; error: %s;", host, snmpError.Error())
+		logging.Error("Failed to query SNMP service for device; ip: %s; error: %s;", host, snmpError.Error())
+		return false, credential, nil
+	}
+
+	results := make(map[string]interface{})
 
-		timeout = time.Duration(1000000000 * appConfig.SnmpTimeoutAp)
+	for _, variable := range snmpResult.Variables {
+		// Cache a reference to the OID without the leading "."
+		resultOid := variable.Name[1:]
 
-		snmp = &gosnmp.GoSNMP{
-			Target:    host,
-			Port:      161,
-			Community: appConfig.SnmpApCommunity,
-			Version:   gosnmp.Version2c,
-			Timeout:   timeout,
+		// Process OID value based on type
+		if variable.Type == gosnmp.OctetString {
+			results[resultOid] = variable.Value.([]byte)
+			logging.Trace2("Loaded string value; ip: %s; oid: %s; value: %s;", host, resultOid, variable.Value)
+		} else if variable.Type == gosnmp.NoSuchObject || variable.Type == gosnmp.NoSuchInstance {
+			logging.Trace1("OID is not supported by device; ip: %s; oid: %s;", host, resultOid)
+		} else {
+			logging.Error("SNMP Exception - Unexpected value type for oid; ip: %s; oid: %s; type: %s;",
+				host, resultOid, variable.Type)
 		}
+	}
 
-		snmpError = snmp.Connect()
+	return len(results) > 0, credential, results
+}
+
+func NormalizeMacAddress(value interface{}) (bool, string) {
+	var mac string
 
-		if snmpError != nil {
-			logging.Warning("Failed to open SNMP connection for device; ip: %s;", host)
-			return false, nil
+	switch v := value.(type) {
+	case []byte:
+		// Raw hardware addresses (IF-MIB::ifPhysAddress) are returned as six octets
+		if len(v) == 6 {
+			return NormalizeMacAddress(hex.EncodeToString(v))
 		}
+		mac = string(v)
+	case string:
+		mac = v
+	default:
+		return false, device.UnknownMacAddress
+	}
 
-		logging.Trace1("Querying SNMP service for device; ip: %s;", host)
+	// Remove any common separators from formats like "0a-00-3e-aa-bb-cc", "0a:00:3e:aa:bb:cc" or "0a00.3eaa.bbcc"
+	mac = strings.ToLower(strings.Trim(mac, " \x00"))
+	mac = strings.NewReplacer("-", "", ":", "", ".", "", " ", "").Replace(mac)
 
-		snmpResult, snmpError = snmp.Get(oids)
+	if len(mac) != 12 {
+		return false, device.UnknownMacAddress
 	}
 
-	if snmpError != nil {
-		logging.Trace1("Failed to query SNMP service for device (ap); ip: %s; error: %s;", host, snmpError.Error())
-		return false, nil
+	if _, err := hex.DecodeString(mac); err != nil || mac == device.UnknownMacAddress {
+		return false, device.UnknownMacAddress
 	}
 
-	for _, variable := range snmpResult.Variables {
-		// Cache a reference to the OID without the leading "."
-		resultOid := variable.Name[1:]
+	return true, mac
+}
 
-		// Process OID value based on type
-		if variable.Type == gosnmp.OctetString {
-			value := strings.Trim(string(variable.Value.([]byte)), " ")
+func ResolveMacAddress(values map[string]interface{}) (bool, string) {
+	// Prefer the Cambium MAC address while falling back to the first interface's physical address
+	for _, oid := range []string{MacAddressOid, InterfaceMacAddressOid} {
+		value, ok := values[oid]
 
-			// Firmware Version & Mode
-			if resultOid == FirmwareModeOid {
-				logging.Trace2("Loaded firmware mode; ip: %s; value: %s;",
-					host, value)
-				return true, value
-			}
-		} else {
-			logging.Error("SNMP Exception - Unexpected value type for oid; ip: %s; oid: %s; type: %s;",
-				host, resultOid, variable.Type)
+		if !ok {
+			continue
+		}
+
+		if success, mac := NormalizeMacAddress(value); success {
+			return true, mac
 		}
 	}
 
-	return false, nil
+	return false, device.UnknownMacAddress
 }
 
-func CheckDevice(ctx context.Context, args interface{}, descriptor workers.JobDescriptor) (interface{}, error) {
-	var record = descriptor.Metadata["record"].(network.Device)
-	argVal := args.(int)
-	mode := "unknown"
-	returnVal := argVal * 2
+func CheckDevice(ctx context.Context, record network.Device,
+	descriptor workers.JobDescriptor) (network.DiscoveryResult, error) {
+	mode := network.DiscoveryModeUnknown
 	timeout := time.Duration(1000000000 * descriptor.AppConfig.ICMPTimeout)
 
+	result := network.DiscoveryResult{
+		Device:     record,
+		MacAddress: device.UnknownMacAddress,
+		Mode:       mode,
+		Outcome:    network.DiscoveryOutcomeUnresponsive,
+	}
+
 	logging.Trace("Testing ICMP for device; "+
 		"id: %v; nid: %v; sid: %v; ipv4: %s; ipv4int: %v; status: %v; timeout: %s;",
 		record.Id, record.NetworkId, record.SubnetId, record.IPv4Address, record.IPv4AddressInt, record.Status,
 		timeout)
 
-	alive := PingHost(descriptor.AppConfig, record.IPv4Address)
+	alive := PingHost(ctx, descriptor.AppConfig, record.IPv4Address)
 
 	if alive == true {
-		success, result := QueryHost(descriptor.AppConfig, record.IPv4Address, FirmwareModeOid)
+		result.Outcome = network.DiscoveryOutcomeUnclassified
+
+		oids := []string{FirmwareModeOid, MacAddressOid, InterfaceMacAddressOid}
+		success, credential, values := QueryHost(ctx, descriptor.AppConfig, record.NetworkId, record.IPv4Address, oids)
+		result.SnmpCredential = credential.Name
 
-		if success == true && result != nil {
-			mode = result.(string)
+		if value, ok := values[FirmwareModeOid]; success == true && ok {
+			mode = strings.Trim(string(value.([]byte)), " ")
 
 			if strings.HasSuffix(mode, " AP") == true {
-				mode = "ap"
+				mode = network.DiscoveryModeAccessPoint
 			} else if strings.HasSuffix(mode, " SM") == true {
-				mode = "sm"
+				mode = network.DiscoveryModeSubscriberModule
+			} else {
+				mode = network.DiscoveryModeUnknown
 			}
 		}
 
+		if found, mac := ResolveMacAddress(values); found {
+			result.MacAddress = mac
+		} else if success == true {
+			logging.Warning("Failed to resolve MAC address for device; host: %s;", record.IPv4Address)
+		}
+
 		if success == true {
-			logging.Trace1("SNMP Query Complete; host: %s; mode: %s;", record.IPv4Address, mode)
+			logging.Trace1("SNMP Query Complete; host: %s; mode: %s; mac: %s;", record.IPv4Address, mode,
+				result.MacAddress)
 		} else {
-			logging.Error("SNMP Query Failed; host: %s;", record.IPv4Address)
+			logging.Debug("SNMP Query Failed; host: %s;", record.IPv4Address)
 		}
 	}
 
-	if mode == "ap" {
-		deviceRecord := device.AccessPoint{
-			NetworkId:      record.NetworkId,
-			MacAddress:     "000000000000",
-			IPv4Address:    record.IPv4Address,
-			IPv4AddressInt: record.IPv4AddressInt,
-			Status:         2,
+	result.Mode = mode
+
+	// Nothing is stored about devices that couldn't be checked in time
+	if err := workers.CheckDeadline(ctx, descriptor, record.IPv4Address); err != nil {
+		return result, err
+	}
+
+	if mode == network.DiscoveryModeAccessPoint {
+		result.Outcome = storeAccessPoint(descriptor, result)
+	}
+
+	if mode == network.DiscoveryModeSubscriberModule {
+		result.Outcome = storeSubscriberModule(descriptor, result)
+	}
+
+	return result, nil
+}
+
+func storeAccessPoint(descriptor workers.JobDescriptor, result network.DiscoveryResult) string {
+	record := result.Device
+	mac := result.MacAddress
+	inventory := descriptor.Metadata["inventory"].(*deviceInventory)
+	outcome := network.DiscoveryOutcomeNew
+
+	deviceRecord := device.AccessPoint{
+		NetworkId:      record.NetworkId,
+		MacAddress:     mac,
+		IPv4Address:    record.IPv4Address,
+		IPv4AddressInt: record.IPv4AddressInt,
+		SnmpCredential: result.SnmpCredential,
+		SnmpStatus:     device.SnmpStatusOk,
+		Status:         2,
+	}
+
+	if existing, found := inventory.findAccessPoint(record.IPv4Address, mac); found {
+		deviceRecord.Id = existing.Id
+		deviceRecord.Status = existing.Status
+
+		// Keep the known MAC address when the device didn't report one during this sweep
+		if mac == device.UnknownMacAddress {
+			deviceRecord.MacAddress = existing.MacAddress
 		}
 
-		dbAp.UpsertRecord(descriptor.Db, deviceRecord)
+		if existing.NetworkId == deviceRecord.NetworkId && existing.MacAddress == deviceRecord.MacAddress &&
+			existing.IPv4Address == deviceRecord.IPv4Address && existing.SnmpCredential == deviceRecord.SnmpCredential &&
+			existing.SnmpStatus == deviceRecord.SnmpStatus {
+			return network.DiscoveryOutcomeUnchanged
+		}
+
+		outcome = network.DiscoveryOutcomeChanged
 	}
 
-	if mode == "sm" {
-		deviceRecord := device.SubscriberModule{
-			NetworkId:      record.NetworkId,
-			MacAddress:     "000000000000",
-			IPv4Address:    record.IPv4Address,
-			IPv4AddressInt: record.IPv4AddressInt,
-			Status:         2,
+	// Disable the subscriber module record when the device has been switched into access point mode
+	if existing, found := inventory.findSubscriberModule(record.IPv4Address, mac); found && existing.Status > 0 {
+		outcome = network.DiscoveryOutcomeChanged
+		existing.Status = 0
+
+		if descriptor.AppConfig.DryRun == false {
+			descriptor.Storage.SubscriberModules.UpdateRecord(existing)
 		}
+	}
 
-		dbSm.UpsertRecord(descriptor.Db, deviceRecord)
+	if descriptor.AppConfig.DryRun == false {
+		descriptor.Storage.AccessPoints.UpsertRecord(deviceRecord)
 	}
 
-	return returnVal, nil
+	return outcome
 }
 
-func BuildDeviceCheckJobs(db *sql.DB, appConfig types.AppConfig, jobId int) (bool, int, []workers.Job) {
-	jobs := make([]workers.Job, 0)
-	success, subnets := dbSubnet.GetRecords(db)
+func storeSubscriberModule(descriptor workers.JobDescriptor, result network.DiscoveryResult) string {
+	record := result.Device
+	mac := result.MacAddress
+	inventory := descriptor.Metadata["inventory"].(*deviceInventory)
+	outcome := network.DiscoveryOutcomeNew
+
+	deviceRecord := device.SubscriberModule{
+		NetworkId:      record.NetworkId,
+		MacAddress:     mac,
+		IPv4Address:    record.IPv4Address,
+		IPv4AddressInt: record.IPv4AddressInt,
+		SnmpCredential: result.SnmpCredential,
+		SnmpStatus:     device.SnmpStatusOk,
+		Status:         2,
+	}
+
+	if existing, found := inventory.findSubscriberModule(record.IPv4Address, mac); found {
+		deviceRecord.Id = existing.Id
+		deviceRecord.Status = existing.Status
+
+		// Keep the known MAC address when the device didn't report one during this sweep
+		if mac == device.UnknownMacAddress {
+			deviceRecord.MacAddress = existing.MacAddress
+		}
+
+		if existing.NetworkId == deviceRecord.NetworkId && existing.MacAddress == deviceRecord.MacAddress &&
+			existing.IPv4Address == deviceRecord.IPv4Address && existing.SnmpCredential == deviceRecord.SnmpCredential &&
+			existing.SnmpStatus == deviceRecord.SnmpStatus {
+			return network.DiscoveryOutcomeUnchanged
+		}
+
+		outcome = network.DiscoveryOutcomeChanged
+	}
+
+	// Disable the access point record when the device has been switched into subscriber module mode
+	if existing, found := inventory.findAccessPoint(record.IPv4Address, mac); found && existing.Status > 0 {
+		outcome = network.DiscoveryOutcomeChanged
+		existing.Status = 0
+
+		if descriptor.AppConfig.DryRun == false {
+			descriptor.Storage.AccessPoints.UpdateRecord(existing)
+		}
+	}
+
+	if descriptor.AppConfig.DryRun == false {
+		descriptor.Storage.SubscriberModules.UpsertRecord(deviceRecord)
+	}
+
+	return outcome
+}
+
+func BuildDeviceCheckJobs(storage *repository.Repositories, appConfig types.AppConfig,
+	jobId int) (bool, int, []workers.Job[network.Device, network.DiscoveryResult]) {
+	jobs := make([]workers.Job[network.Device, network.DiscoveryResult], 0)
+	success, subnets := storage.Subnets.GetRecords()
 
 	if jobId < 1 {
 		jobId = 1
@@ -219,6 +363,21 @@ func BuildDeviceCheckJobs(db *sql.DB, appConfig types.AppConfig, jobId int) (boo
 		return false, jobId, jobs
 	}
 
+	success, accessPointRecords := storage.AccessPoints.GetRecords()
+
+	if success != true {
+		return false, jobId, jobs
+	}
+
+	success, subscriberModuleRecords := storage.SubscriberModules.GetRecords()
+
+	if success != true {
+		return false, jobId, jobs
+	}
+
+	// Index the existing inventory so discovered devices can be compared against it
+	inventory := newDeviceInventory(accessPointRecords, subscriberModuleRecords)
+
 	for _, el := range subnets {
 		if el.Status < 1 {
 			continue
@@ -231,6 +390,12 @@ func BuildDeviceCheckJobs(db *sql.DB, appConfig types.AppConfig, jobId int) (boo
 			ipv4StartInt, _ := ipconv.IPv4ToInt(ipv4Start)
 			ipv4EndInt, _ := ipconv.IPv4ToInt(ipv4End)
 
+			// Skip the network and broadcast addresses for subnets that have them
+			if el.IPv4NetworkMask < 31 {
+				ipv4StartInt++
+				ipv4EndInt--
+			}
+
 			for i := ipv4StartInt; i <= ipv4EndInt; i++ {
 				ipv4Address := ipconv.IntToIPv4(i).String()
 
@@ -243,18 +408,19 @@ func BuildDeviceCheckJobs(db *sql.DB, appConfig types.AppConfig, jobId int) (boo
 				}
 
 				metadata := make(map[string]interface{})
-				metadata["record"] = networkDevice
+				metadata["inventory"] = inventory
 
-				job := workers.Job{
+				job := workers.Job[network.Device, network.DiscoveryResult]{
 					Descriptor: workers.JobDescriptor{
 						ID:        workers.JobID(fmt.Sprintf("%v", jobId)),
 						JType:     "icmp",
 						AppConfig: appConfig,
 						Metadata:  metadata,
-						Db:        db,
+						Storage:   storage,
+						Timeout:   time.Duration(1000000000 * appConfig.JobTimeout),
 					},
 					ExecFn: CheckDevice,
-					Args:   jobId,
+					Args:   networkDevice,
 				}
 
 				logging.Trace("Building ICMP job for device (%v); nid: %v; sid: %v; ip: %s; ipInt: %v; status: %v;",
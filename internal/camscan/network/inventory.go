@@ -0,0 +1,67 @@
+package network
+
+import (
+	"as/camscan/internal/camscan/types/device"
+)
+
+// deviceInventory indexes the known devices by MAC address and IPv4 address for discovery lookups. It is shared by
+// all discovery jobs and must be treated as read-only once built.
+type deviceInventory struct {
+	accessPointsByIPv4      map[string]device.AccessPoint
+	accessPointsByMac       map[string]device.AccessPoint
+	subscriberModulesByIPv4 map[string]device.SubscriberModule
+	subscriberModulesByMac  map[string]device.SubscriberModule
+}
+
+func newDeviceInventory(accessPoints []device.AccessPoint, subscriberModules []device.SubscriberModule) *deviceInventory {
+	inventory := &deviceInventory{
+		accessPointsByIPv4:      make(map[string]device.AccessPoint),
+		accessPointsByMac:       make(map[string]device.AccessPoint),
+		subscriberModulesByIPv4: make(map[string]device.SubscriberModule),
+		subscriberModulesByMac:  make(map[string]device.SubscriberModule),
+	}
+
+	for _, el := range accessPoints {
+		inventory.accessPointsByIPv4[el.IPv4Address] = el
+		if el.MacAddress != device.UnknownMacAddress {
+			inventory.accessPointsByMac[el.MacAddress] = el
+		}
+	}
+
+	for _, el := range subscriberModules {
+		inventory.subscriberModulesByIPv4[el.IPv4Address] = el
+		if el.MacAddress != device.UnknownMacAddress {
+			inventory.subscriberModulesByMac[el.MacAddress] = el
+		}
+	}
+
+	return inventory
+}
+
+func (i *deviceInventory) findAccessPoint(ipv4 string, mac string) (device.AccessPoint, bool) {
+	if record, found := i.accessPointsByMac[mac]; found {
+		return record, true
+	}
+
+	// Only match by address when the record at the address could belong to the same device
+	record, found := i.accessPointsByIPv4[ipv4]
+	if found && (mac == device.UnknownMacAddress || record.MacAddress == device.UnknownMacAddress) {
+		return record, true
+	}
+
+	return device.AccessPoint{}, false
+}
+
+func (i *deviceInventory) findSubscriberModule(ipv4 string, mac string) (device.SubscriberModule, bool) {
+	if record, found := i.subscriberModulesByMac[mac]; found {
+		return record, true
+	}
+
+	// Only match by address when the record at the address could belong to the same device
+	record, found := i.subscriberModulesByIPv4[ipv4]
+	if found && (mac == device.UnknownMacAddress || record.MacAddress == device.UnknownMacAddress) {
+		return record, true
+	}
+
+	return device.SubscriberModule{}, false
+}
@@ -0,0 +1,334 @@
+package snmp
+
+import (
+	"as/camscan/internal/camscan/logging"
+	"as/camscan/internal/camscan/types"
+	snmpTypes "as/camscan/internal/camscan/types/snmp"
+	"as/camscan/internal/camscan/workers"
+	"context"
+	"errors"
+	"fmt"
+	"github.com/gosnmp/gosnmp"
+	"strings"
+	"time"
+)
+
+const DefaultPort = 161
+const SysUpTimeOid = "1.3.6.1.2.1.1.3.0"
+
+var ErrNoCredential = errors.New("no SNMP credential was accepted by the device")
+
+var authProtocols = map[string]gosnmp.SnmpV3AuthProtocol{
+	"":       gosnmp.NoAuth,
+	"NONE":   gosnmp.NoAuth,
+	"MD5":    gosnmp.MD5,
+	"SHA":    gosnmp.SHA,
+	"SHA224": gosnmp.SHA224,
+	"SHA256": gosnmp.SHA256,
+	"SHA384": gosnmp.SHA384,
+	"SHA512": gosnmp.SHA512,
+}
+
+var privProtocols = map[string]gosnmp.SnmpV3PrivProtocol{
+	"":        gosnmp.NoPriv,
+	"NONE":    gosnmp.NoPriv,
+	"DES":     gosnmp.DES,
+	"AES":     gosnmp.AES,
+	"AES192":  gosnmp.AES192,
+	"AES256":  gosnmp.AES256,
+	"AES192C": gosnmp.AES192C,
+	"AES256C": gosnmp.AES256C,
+}
+
+// GetCredential builds the SNMP credential for a device of the given type on the given network. The global settings
+// are used unless the network defines its own override for a setting.
+func GetCredential(appConfig types.AppConfig, networkId int, deviceType int) snmpTypes.Credential {
+	suffix := "-sm"
+
+	if deviceType == snmpTypes.DeviceTypeAccessPoint {
+		suffix = "-ap"
+	}
+
+	credential := snmpTypes.Credential{
+		Name:           "default" + suffix,
+		Version:        appConfig.SnmpVersion,
+		Community:      appConfig.SnmpSmCommunity,
+		User:           appConfig.SnmpV3User,
+		AuthProtocol:   appConfig.SnmpV3AuthProtocol,
+		AuthPassphrase: appConfig.SnmpV3AuthPassphrase,
+		PrivProtocol:   appConfig.SnmpV3PrivProtocol,
+		PrivPassphrase: appConfig.SnmpV3PrivPassphrase,
+	}
+
+	if deviceType == snmpTypes.DeviceTypeAccessPoint {
+		credential.Community = appConfig.SnmpApCommunity
+	}
+
+	override, ok := appConfig.SnmpNetworks[networkId]
+
+	if !ok {
+		return credential
+	}
+
+	credential.Name = override.Name + suffix
+
+	if override.Version != "" {
+		credential.Version = override.Version
+	}
+
+	if override.Community != "" {
+		credential.Community = override.Community
+	}
+
+	if override.User != "" {
+		credential.User = override.User
+		credential.AuthProtocol = override.AuthProtocol
+		credential.AuthPassphrase = override.AuthPassphrase
+		credential.PrivProtocol = override.PrivProtocol
+		credential.PrivPassphrase = override.PrivPassphrase
+	}
+
+	return credential
+}
+
+// GetCredentials builds the ordered list of SNMP credentials to try for a device of the given type on the given
+// network. The device's own credential comes first, followed by the other device type's credential and then every
+// configured credential set. The credential named by preferred, typically the one that last worked for the device, is
+// moved to the front of the list when present.
+func GetCredentials(appConfig types.AppConfig, networkId int, deviceType int, preferred string) []snmpTypes.Credential {
+	otherType := snmpTypes.DeviceTypeAccessPoint
+
+	if deviceType == snmpTypes.DeviceTypeAccessPoint {
+		otherType = snmpTypes.DeviceTypeSubscriberModule
+	}
+
+	candidates := []snmpTypes.Credential{
+		GetCredential(appConfig, networkId, deviceType),
+		GetCredential(appConfig, networkId, otherType),
+	}
+	candidates = append(candidates, appConfig.SnmpCredentials...)
+
+	credentials := make([]snmpTypes.Credential, 0, len(candidates))
+
+	for _, candidate := range candidates {
+		duplicate := false
+
+		for _, credential := range credentials {
+			if sameCredential(candidate, credential) {
+				duplicate = true
+				break
+			}
+		}
+
+		if duplicate {
+			continue
+		}
+
+		if candidate.Name == preferred {
+			credentials = append([]snmpTypes.Credential{candidate}, credentials...)
+		} else {
+			credentials = append(credentials, candidate)
+		}
+	}
+
+	return credentials
+}
+
+// sameCredential determines whether two credentials would authenticate identically, regardless of their names.
+func sameCredential(a snmpTypes.Credential, b snmpTypes.Credential) bool {
+	a.Name = ""
+	b.Name = ""
+
+	if a.Version != snmpTypes.Version3 && b.Version != snmpTypes.Version3 {
+		return a.Community == b.Community
+	}
+
+	a.Community = ""
+	b.Community = ""
+
+	return a == b
+}
+
+// ConnectWithCredentials tries each of the given credentials in turn until the device answers a query for its
+// uptime. The connected client and the credential that worked are returned. The caller is responsible for closing
+// the client's connection. Every request made with the client is aborted once the given context is done, in which
+// case the context's error is returned instead of ErrNoCredential. When the device never answered, the returned error
+// also wraps ErrUnreachable.
+func ConnectWithCredentials(ctx context.Context, host string, credentials []snmpTypes.Credential,
+	timeout time.Duration) (*gosnmp.GoSNMP, snmpTypes.Credential, error) {
+	var lastError error = nil
+	answered := false
+
+	for _, credential := range credentials {
+		if ctx.Err() != nil {
+			return nil, snmpTypes.Credential{}, ctx.Err()
+		}
+
+		client, err := Connect(ctx, host, credential, timeout)
+
+		if err != nil {
+			lastError = err
+			answered = answered || !IsNoResponse(err)
+			continue
+		}
+
+		_, err = client.Get([]string{SysUpTimeOid})
+
+		if err == nil {
+			return client, credential, nil
+		}
+
+		logging.Trace1("SNMP credential was not accepted by device; ip: %s; credential: %s; version: %s; error: %s;",
+			host, credential.Name, credential.Version, err.Error())
+
+		_ = client.Conn.Close()
+
+		// A request that ran out of time says nothing about whether the credential is valid
+		if ctx.Err() != nil {
+			return nil, snmpTypes.Credential{}, ctx.Err()
+		}
+
+		lastError = err
+		answered = answered || !IsNoResponse(err)
+	}
+
+	if lastError == nil {
+		return nil, snmpTypes.Credential{}, ErrNoCredential
+	}
+
+	if !answered {
+		return nil, snmpTypes.Credential{}, fmt.Errorf("%w; %w; %s", ErrNoCredential, ErrUnreachable, lastError.Error())
+	}
+
+	return nil, snmpTypes.Credential{}, fmt.Errorf("%w; %s", ErrNoCredential, lastError.Error())
+}
+
+// WalkTable retrieves every row of the table below the given table entry OID, e.g. IF-MIB::ifEntry
+// (1.3.6.1.2.1.2.2.1), using bulk requests. The rows are keyed by row index and each row's values are keyed by column
+// number.
+func WalkTable(client *gosnmp.GoSNMP, entryOid string) (snmpTypes.Table, error) {
+	table := make(snmpTypes.Table)
+	prefix := "." + strings.Trim(entryOid, ".") + "."
+
+	variables, err := client.BulkWalkAll(entryOid)
+
+	if err != nil {
+		return table, err
+	}
+
+	for _, variable := range variables {
+		if !strings.HasPrefix(variable.Name, prefix) {
+			continue
+		}
+
+		column, index, found := strings.Cut(strings.TrimPrefix(variable.Name, prefix), ".")
+
+		if !found {
+			logging.Trace1("Skipping table value without a row index; ip: %s; oid: %s;", client.Target, variable.Name)
+			continue
+		}
+
+		value, ok := Decode(variable)
+
+		if !ok {
+			continue
+		}
+
+		if _, ok := table[index]; !ok {
+			table[index] = make(snmpTypes.TableRow)
+		}
+
+		table[index][column] = value
+	}
+
+	return table, nil
+}
+
+// NewClient creates an SNMP client for the given host using the given credential. The client must still be connected
+// by the caller.
+func NewClient(host string, credential snmpTypes.Credential, timeout time.Duration) (*gosnmp.GoSNMP, error) {
+	client := &gosnmp.GoSNMP{
+		Target:  host,
+		Port:    DefaultPort,
+		Timeout: timeout,
+	}
+
+	if credential.Version != snmpTypes.Version3 {
+		client.Version = gosnmp.Version2c
+		client.Community = credential.Community
+		return client, nil
+	}
+
+	authProtocol, ok := authProtocols[credential.AuthProtocol]
+
+	if !ok {
+		return nil, fmt.Errorf("unsupported SNMPv3 authentication protocol '%s'", credential.AuthProtocol)
+	}
+
+	privProtocol, ok := privProtocols[credential.PrivProtocol]
+
+	if !ok {
+		return nil, fmt.Errorf("unsupported SNMPv3 privacy protocol '%s'", credential.PrivProtocol)
+	}
+
+	if credential.User == "" {
+		return nil, fmt.Errorf("SNMPv3 credential '%s' is missing a user name", credential.Name)
+	}
+
+	msgFlags := gosnmp.NoAuthNoPriv
+
+	if authProtocol != gosnmp.NoAuth && privProtocol != gosnmp.NoPriv {
+		msgFlags = gosnmp.AuthPriv
+	} else if authProtocol != gosnmp.NoAuth {
+		msgFlags = gosnmp.AuthNoPriv
+	} else if privProtocol != gosnmp.NoPriv {
+		return nil, fmt.Errorf("SNMPv3 credential '%s' defines privacy without authentication", credential.Name)
+	}
+
+	client.Version = gosnmp.Version3
+	client.SecurityModel = gosnmp.UserSecurityModel
+	client.MsgFlags = msgFlags
+	client.SecurityParameters = &gosnmp.UsmSecurityParameters{
+		UserName:                 credential.User,
+		AuthenticationProtocol:   authProtocol,
+		AuthenticationPassphrase: credential.AuthPassphrase,
+		PrivacyProtocol:          privProtocol,
+		PrivacyPassphrase:        credential.PrivPassphrase,
+	}
+
+	return client, nil
+}
+
+// Connect creates an SNMP client for the given host and opens its connection. Requests made with the client are
+// aborted once the given context is done, and are held back by the packet rate limit of the worker pool executing the
+// job of the given context.
+func Connect(ctx context.Context, host string, credential snmpTypes.Credential,
+	timeout time.Duration) (*gosnmp.GoSNMP, error) {
+	client, err := NewClient(host, credential, timeout)
+
+	if err != nil {
+		return nil, err
+	}
+
+	client.Context = ctx
+	client.PreSend = func(client *gosnmp.GoSNMP) {
+		if workers.WaitForPacket(ctx) != nil {
+			return
+		}
+
+		// The request's deadline was set before waiting, so restart it to keep the wait from eating into the timeout
+		deadline := time.Now().Add(client.Timeout)
+
+		if ctxDeadline, ok := ctx.Deadline(); ok && ctxDeadline.Before(deadline) {
+			deadline = ctxDeadline
+		}
+
+		_ = client.Conn.SetDeadline(deadline)
+	}
+
+	if err = client.Connect(); err != nil {
+		return nil, err
+	}
+
+	return client, nil
+}
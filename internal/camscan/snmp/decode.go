@@ -0,0 +1,113 @@
+package snmp
+
+import (
+	snmpTypes "as/camscan/internal/camscan/types/snmp"
+	"encoding/hex"
+	"github.com/gosnmp/gosnmp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Decode converts an SNMP variable of any type into a typed value. Numbers are stored in SnmpValueNum, while text is
+// stored in SnmpValueChar or, when it's too long, in SnmpValueText. Binary octet strings like MAC addresses are
+// rendered as colon separated hex. TimeTicks durations are also converted into the unix timestamp that they started
+// at, i.e. the last boot for uptime OIDs. False is returned when the variable holds no value, e.g. Null or NoSuchObject.
+func Decode(variable gosnmp.SnmpPDU) (snmpTypes.Value, bool) {
+	value := snmpTypes.Value{
+		SnmpType: int(variable.Type),
+	}
+
+	switch variable.Type {
+	case gosnmp.Integer, gosnmp.Counter32, gosnmp.Gauge32, gosnmp.Uinteger32:
+		value.SnmpValueNum = float64(gosnmp.ToBigInt(variable.Value).Int64())
+	case gosnmp.TimeTicks:
+		value.SnmpValueNum = float64(gosnmp.ToBigInt(variable.Value).Int64())
+		value.LastBoot = int(time.Now().Add(-value.Duration()).Unix())
+	case gosnmp.Counter64:
+		number := gosnmp.ToBigInt(variable.Value)
+		value.SnmpValueNum, _ = strconv.ParseFloat(number.String(), 64)
+		value.SnmpValueChar = number.String()
+	case gosnmp.OpaqueFloat:
+		number, ok := variable.Value.(float32)
+		if !ok {
+			return value, false
+		}
+		value.SnmpValueNum = float64(number)
+	case gosnmp.OpaqueDouble:
+		number, ok := variable.Value.(float64)
+		if !ok {
+			return value, false
+		}
+		value.SnmpValueNum = number
+	case gosnmp.OctetString, gosnmp.Opaque, gosnmp.BitString, gosnmp.NsapAddress:
+		raw, ok := variable.Value.([]byte)
+		if !ok {
+			return value, false
+		}
+		setText(&value, decodeOctets(raw, variable.Type == gosnmp.OctetString))
+	case gosnmp.IPAddress, gosnmp.ObjectIdentifier, gosnmp.ObjectDescription:
+		text, ok := variable.Value.(string)
+		if !ok {
+			return value, false
+		}
+		setText(&value, strings.TrimPrefix(text, "."))
+	case gosnmp.Boolean:
+		number, ok := variable.Value.(bool)
+		if !ok {
+			return value, false
+		}
+		value.SnmpType = snmpTypes.TypeInteger
+		if number {
+			value.SnmpValueNum = 1
+		}
+	default:
+		// Null, NoSuchObject, NoSuchInstance, EndOfMibView and unknown types don't carry a value
+		return value, false
+	}
+
+	return value, true
+}
+
+// decodeOctets renders printable octet strings as trimmed text and anything else as colon separated hex.
+func decodeOctets(raw []byte, allowText bool) string {
+	if allowText && isPrintable(raw) {
+		return strings.Trim(string(raw), " \x00")
+	}
+
+	encoded := hex.EncodeToString(raw)
+	pairs := make([]string, 0, len(raw))
+
+	for i := 0; i+1 < len(encoded); i += 2 {
+		pairs = append(pairs, encoded[i:i+2])
+	}
+
+	return strings.Join(pairs, ":")
+}
+
+func isPrintable(raw []byte) bool {
+	// Trailing NUL padding is common for fixed length strings and doesn't make the value binary
+	text := strings.TrimRight(string(raw), "\x00")
+
+	if !utf8.ValidString(text) {
+		return false
+	}
+
+	for _, r := range text {
+		if !unicode.IsPrint(r) && !unicode.IsSpace(r) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func setText(value *snmpTypes.Value, text string) {
+	if len(text) > snmpTypes.MaxValueCharLength {
+		value.SnmpValueText = text
+	} else {
+		value.SnmpValueChar = text
+	}
+}
@@ -0,0 +1,38 @@
+package snmp
+
+import (
+	"context"
+	"errors"
+	"net"
+	"strings"
+)
+
+var ErrUnreachable = errors.New("the device didn't answer any SNMP request")
+
+// PollError reports a device that couldn't be polled completely. Reason is one of the snmp.Failure* reasons of the
+// types package.
+type PollError struct {
+	Reason string
+	Err    error
+}
+
+func (e *PollError) Error() string {
+	return e.Reason + ": " + e.Err.Error()
+}
+
+func (e *PollError) Unwrap() error {
+	return e.Err
+}
+
+// IsNoResponse determines whether the given error means the device never answered the request, as opposed to having
+// answered with an error.
+func IsNoResponse(err error) bool {
+	var netError net.Error
+
+	if errors.Is(err, context.DeadlineExceeded) || errors.As(err, &netError) {
+		return true
+	}
+
+	// The request timeouts of gosnmp are plain errors
+	return strings.Contains(err.Error(), "timeout")
+}
@@ -0,0 +1,134 @@
+package snmp
+
+import (
+	"as/camscan/internal/camscan/logging"
+	"errors"
+	"fmt"
+	"github.com/gosnmp/gosnmp"
+	"strings"
+)
+
+var ErrNoSuchName = errors.New("no such name")
+var ErrNoSuchObject = errors.New("no such object")
+var ErrNoSuchInstance = errors.New("no such instance")
+
+// IsMissing determines whether the given error means the device doesn't provide an OID, as opposed to the request
+// having failed.
+func IsMissing(err error) bool {
+	return errors.Is(err, ErrNoSuchName) || errors.Is(err, ErrNoSuchObject) || errors.Is(err, ErrNoSuchInstance)
+}
+
+// GetAll retrieves the given OIDs using as many get requests as needed to respect the client's MaxOids setting. When
+// the device responds with a tooBig error the request is split in half and retried, and when it responds with a
+// noSuchName error the offending OID is dropped and the request is retried without it. The values that could be
+// retrieved are returned along with an error for every OID that could not be.
+func GetAll(client *gosnmp.GoSNMP, oids []string) ([]gosnmp.SnmpPDU, map[string]error) {
+	variables := make([]gosnmp.SnmpPDU, 0, len(oids))
+	failures := make(map[string]error)
+	chunkSize := client.MaxOids
+
+	if chunkSize <= 0 {
+		chunkSize = gosnmp.MaxOids
+	}
+
+	for start := 0; start < len(oids); start += chunkSize {
+		end := start + chunkSize
+
+		if end > len(oids) {
+			end = len(oids)
+		}
+
+		if err := getChunk(client, client.Target, oids[start:end], &variables, failures); err != nil {
+			// Transport errors like timeouts won't be resolved by further requests, so fail the remaining OIDs
+			for _, oid := range oids[end:] {
+				failures[oid] = err
+			}
+			break
+		}
+	}
+
+	return variables, failures
+}
+
+// getter is the part of an SNMP client that chunks of OIDs are retrieved with
+type getter interface {
+	Get(oids []string) (*gosnmp.SnmpPacket, error)
+}
+
+// getChunk retrieves a single chunk of OIDs from the device at the given target, splitting it further as needed. A
+// transport error is returned when the device couldn't be queried at all.
+func getChunk(client getter, target string, oids []string, variables *[]gosnmp.SnmpPDU,
+	failures map[string]error) error {
+	if len(oids) == 0 {
+		return nil
+	}
+
+	result, err := client.Get(oids)
+
+	if err != nil {
+		for _, oid := range oids {
+			failures[oid] = err
+		}
+		return err
+	}
+
+	switch result.Error {
+	case gosnmp.NoError:
+		for _, variable := range result.Variables {
+			oid := strings.TrimPrefix(variable.Name, ".")
+
+			switch variable.Type {
+			case gosnmp.NoSuchObject:
+				failures[oid] = ErrNoSuchObject
+			case gosnmp.NoSuchInstance:
+				failures[oid] = ErrNoSuchInstance
+			default:
+				*variables = append(*variables, variable)
+			}
+		}
+		return nil
+	case gosnmp.TooBig:
+		if len(oids) > 1 {
+			logging.Trace1("Splitting SNMP request that was too big; ip: %s; oids: %v;", target, len(oids))
+			return splitChunk(client, target, oids, variables, failures)
+		}
+	case gosnmp.NoSuchName:
+		// The error index identifies the offending OID starting from one
+		index := int(result.ErrorIndex) - 1
+
+		if index >= 0 && index < len(oids) {
+			failures[oids[index]] = ErrNoSuchName
+			remaining := append(append(make([]string, 0, len(oids)-1), oids[:index]...), oids[index+1:]...)
+			return getChunk(client, target, remaining, variables, failures)
+		}
+
+		if len(oids) > 1 {
+			return splitChunk(client, target, oids, variables, failures)
+		}
+
+		failures[oids[0]] = ErrNoSuchName
+		return nil
+	default:
+		if len(oids) > 1 {
+			return splitChunk(client, target, oids, variables, failures)
+		}
+	}
+
+	failures[oids[0]] = fmt.Errorf("SNMP error %s", result.Error)
+
+	return nil
+}
+
+func splitChunk(client getter, target string, oids []string, variables *[]gosnmp.SnmpPDU,
+	failures map[string]error) error {
+	middle := len(oids) / 2
+
+	if err := getChunk(client, target, oids[:middle], variables, failures); err != nil {
+		for _, oid := range oids[middle:] {
+			failures[oid] = err
+		}
+		return err
+	}
+
+	return getChunk(client, target, oids[middle:], variables, failures)
+}
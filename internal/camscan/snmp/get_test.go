@@ -0,0 +1,212 @@
+package snmp
+
+import (
+	"errors"
+	"github.com/gosnmp/gosnmp"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+)
+
+var errFakeTimeout = errors.New("request timeout")
+
+// fakeDevice answers get requests the way an SNMPv1 device does, reporting only the first OID it doesn't provide
+type fakeDevice struct {
+	maxOids    int             // Requests for more OIDs than this are answered with tooBig, unless it's zero
+	missing    map[string]bool // OIDs answered with noSuchName
+	noSuchObj  map[string]bool // OIDs answered with a noSuchObject value, the way SNMPv2c devices do
+	unindexed  bool            // Whether noSuchName is answered without identifying the offending OID
+	unanswered string          // OID whose requests time out
+	requests   [][]string
+}
+
+func (d *fakeDevice) Get(oids []string) (*gosnmp.SnmpPacket, error) {
+	d.requests = append(d.requests, append([]string(nil), oids...))
+
+	if d.maxOids != 0 && len(oids) > d.maxOids {
+		return &gosnmp.SnmpPacket{Error: gosnmp.TooBig}, nil
+	}
+
+	for _, oid := range oids {
+		if oid == d.unanswered {
+			return nil, errFakeTimeout
+		}
+	}
+
+	for i, oid := range oids {
+		if !d.missing[oid] {
+			continue
+		}
+
+		if d.unindexed {
+			return &gosnmp.SnmpPacket{Error: gosnmp.NoSuchName}, nil
+		}
+
+		return &gosnmp.SnmpPacket{Error: gosnmp.NoSuchName, ErrorIndex: uint8(i + 1)}, nil
+	}
+
+	variables := make([]gosnmp.SnmpPDU, 0, len(oids))
+
+	for _, oid := range oids {
+		if d.noSuchObj[oid] {
+			variables = append(variables, gosnmp.SnmpPDU{Name: "." + oid, Type: gosnmp.NoSuchObject})
+			continue
+		}
+
+		variables = append(variables, gosnmp.SnmpPDU{Name: "." + oid, Type: gosnmp.OctetString, Value: []byte(oid)})
+	}
+
+	return &gosnmp.SnmpPacket{Error: gosnmp.NoError, Variables: variables}, nil
+}
+
+func TestGetChunk(t *testing.T) {
+	tests := []struct {
+		name     string
+		device   fakeDevice
+		oids     []string
+		values   []string
+		failures map[string]error
+		requests int
+		err      error
+	}{
+		{
+			name:     "all provided",
+			oids:     []string{"1.1", "1.2", "1.3"},
+			values:   []string{"1.1", "1.2", "1.3"},
+			failures: map[string]error{},
+			requests: 1,
+		},
+		{
+			name:     "too big is split in half",
+			device:   fakeDevice{maxOids: 2},
+			oids:     []string{"1.1", "1.2", "1.3", "1.4", "1.5"},
+			values:   []string{"1.1", "1.2", "1.3", "1.4", "1.5"},
+			failures: map[string]error{},
+			requests: 5,
+		},
+		{
+			name:     "no such name drops the offending OID",
+			device:   fakeDevice{missing: map[string]bool{"1.2": true}},
+			oids:     []string{"1.1", "1.2", "1.3", "1.4"},
+			values:   []string{"1.1", "1.3", "1.4"},
+			failures: map[string]error{"1.2": ErrNoSuchName},
+			requests: 2,
+		},
+		{
+			name:     "no such name within a split chunk",
+			device:   fakeDevice{maxOids: 2, missing: map[string]bool{"1.4": true}},
+			oids:     []string{"1.1", "1.2", "1.3", "1.4", "1.5"},
+			values:   []string{"1.1", "1.2", "1.3", "1.5"},
+			failures: map[string]error{"1.4": ErrNoSuchName},
+			requests: 6,
+		},
+		{
+			name:     "no such name without an index is split until found",
+			device:   fakeDevice{missing: map[string]bool{"1.3": true}, unindexed: true},
+			oids:     []string{"1.1", "1.2", "1.3", "1.4"},
+			values:   []string{"1.1", "1.2", "1.4"},
+			failures: map[string]error{"1.3": ErrNoSuchName},
+			requests: 5,
+		},
+		{
+			name:     "no such object value",
+			device:   fakeDevice{noSuchObj: map[string]bool{"1.1": true}},
+			oids:     []string{"1.1", "1.2"},
+			values:   []string{"1.2"},
+			failures: map[string]error{"1.1": ErrNoSuchObject},
+			requests: 1,
+		},
+		{
+			name:     "too big for a single OID",
+			device:   fakeDevice{maxOids: -1},
+			oids:     []string{"1.1"},
+			values:   []string{},
+			failures: map[string]error{"1.1": errors.New("SNMP error TooBig")},
+			requests: 1,
+		},
+		{
+			name:   "timeout fails the rest of a split chunk without requesting it",
+			device: fakeDevice{maxOids: 2, unanswered: "1.1"},
+			oids:   []string{"1.1", "1.2", "1.3", "1.4"},
+			values: []string{},
+			failures: map[string]error{
+				"1.1": errFakeTimeout,
+				"1.2": errFakeTimeout,
+				"1.3": errFakeTimeout,
+				"1.4": errFakeTimeout,
+			},
+			requests: 2,
+			err:      errFakeTimeout,
+		},
+		{
+			name:     "timeout in the second half of a split chunk",
+			device:   fakeDevice{maxOids: 2, unanswered: "1.4"},
+			oids:     []string{"1.1", "1.2", "1.3", "1.4"},
+			values:   []string{"1.1", "1.2"},
+			failures: map[string]error{"1.3": errFakeTimeout, "1.4": errFakeTimeout},
+			requests: 3,
+			err:      errFakeTimeout,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			device := test.device
+			variables := make([]gosnmp.SnmpPDU, 0)
+			failures := make(map[string]error)
+
+			err := getChunk(&device, "192.0.2.1", test.oids, &variables, failures)
+
+			if !errors.Is(err, test.err) {
+				t.Errorf("error = %v, want %v", err, test.err)
+			}
+
+			values := make([]string, 0, len(variables))
+
+			for _, variable := range variables {
+				values = append(values, strings.TrimPrefix(variable.Name, "."))
+			}
+
+			sort.Strings(values)
+
+			if !reflect.DeepEqual(values, test.values) {
+				t.Errorf("values = %v, want %v", values, test.values)
+			}
+
+			if len(failures) != len(test.failures) {
+				t.Errorf("failures = %v, want %v", failures, test.failures)
+			}
+
+			for oid, want := range test.failures {
+				if got := failures[oid]; got == nil || (!errors.Is(got, want) && got.Error() != want.Error()) {
+					t.Errorf("failure of %s = %v, want %v", oid, got, want)
+				}
+			}
+
+			if len(device.requests) != test.requests {
+				t.Errorf("requests = %v, want %v", device.requests, test.requests)
+			}
+		})
+	}
+}
+
+func TestSplitChunk(t *testing.T) {
+	device := fakeDevice{missing: map[string]bool{"1.1": true}}
+	variables := make([]gosnmp.SnmpPDU, 0)
+	failures := make(map[string]error)
+
+	if err := splitChunk(&device, "192.0.2.1", []string{"1.1", "1.2", "1.3"}, &variables, failures); err != nil {
+		t.Fatalf("error = %v, want nil", err)
+	}
+
+	want := [][]string{{"1.1"}, {"1.2", "1.3"}}
+
+	if !reflect.DeepEqual(device.requests, want) {
+		t.Errorf("requests = %v, want %v", device.requests, want)
+	}
+
+	if len(variables) != 2 || !errors.Is(failures["1.1"], ErrNoSuchName) {
+		t.Errorf("variables = %v, failures = %v", variables, failures)
+	}
+}
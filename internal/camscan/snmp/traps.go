@@ -0,0 +1,109 @@
+package snmp
+
+import (
+	"as/camscan/internal/camscan/logging"
+	snmpTypes "as/camscan/internal/camscan/types/snmp"
+	"fmt"
+	"github.com/gosnmp/gosnmp"
+	"strconv"
+	"strings"
+)
+
+const SnmpTrapOid = "1.3.6.1.6.3.1.1.4.1.0"
+
+// genericTrapOids is the OID prefix that SNMPv2 maps the generic traps of SNMPv1, i.e. coldStart to egpNeighborLoss,
+// to by appending the generic trap number plus one
+const genericTrapOids = "1.3.6.1.6.3.1.1.5"
+
+// enterpriseSpecificTrap is the SNMPv1 generic trap number of traps that are identified by their specific trap number
+const enterpriseSpecificTrap = 6
+
+// trapLogger passes the packet level messages of the trap listener on to the application log
+type trapLogger struct{}
+
+func (trapLogger) Print(v ...interface{}) {
+	logging.Trace9("%s", strings.TrimSpace(fmt.Sprint(v...)))
+}
+
+func (trapLogger) Printf(format string, v ...interface{}) {
+	logging.Trace9("%s", strings.TrimSpace(fmt.Sprintf(format, v...)))
+}
+
+// NewTrapListener creates a listener for traps and informs, which answers informs once they're handled. SNMPv3
+// notifications are authenticated and decrypted with the given credential when it's an SNMPv3 credential, while v1
+// and v2c notifications are accepted regardless of their community, which is left for the handler to check.
+func NewTrapListener(credential snmpTypes.Credential) (*gosnmp.TrapListener, error) {
+	params, err := NewClient("", credential, 0)
+
+	if err != nil {
+		return nil, err
+	}
+
+	params.Logger = gosnmp.NewLogger(trapLogger{})
+
+	listener := gosnmp.NewTrapListener()
+	listener.Params = params
+
+	return listener, nil
+}
+
+// DecodeTrap converts a received trap or inform into an event, identified by its SNMPv2 notification OID. The trap
+// OID of SNMPv1 traps is derived from their generic and specific trap numbers like SNMPv2 proxies do, while the uptime
+// and trap OID variables of SNMPv2 notifications are moved into the event rather than kept as values. Variables that
+// hold no value are skipped. The source address, device and timestamp are left for the caller to fill in.
+func DecodeTrap(packet *gosnmp.SnmpPacket) snmpTypes.Event {
+	event := snmpTypes.Event{
+		PduType: int(packet.PDUType),
+		Values:  make([]snmpTypes.EventValue, 0, len(packet.Variables)),
+	}
+
+	switch packet.Version {
+	case gosnmp.Version1:
+		event.Version = snmpTypes.Version1
+	case gosnmp.Version3:
+		event.Version = snmpTypes.Version3
+	default:
+		event.Version = snmpTypes.Version2c
+	}
+
+	if packet.PDUType == gosnmp.Trap {
+		event.Uptime = int(packet.Timestamp)
+		event.TrapOid = trapV1Oid(packet.SnmpTrap)
+	}
+
+	for _, variable := range packet.Variables {
+		oid := strings.TrimPrefix(variable.Name, ".")
+
+		switch oid {
+		case SysUpTimeOid:
+			if variable.Type == gosnmp.TimeTicks {
+				event.Uptime = int(gosnmp.ToBigInt(variable.Value).Int64())
+				continue
+			}
+		case SnmpTrapOid:
+			if text, ok := variable.Value.(string); ok {
+				event.TrapOid = strings.TrimPrefix(text, ".")
+				continue
+			}
+		}
+
+		value, ok := Decode(variable)
+
+		if !ok {
+			continue
+		}
+
+		event.Values = append(event.Values, snmpTypes.EventValue{Oid: oid, Value: value})
+	}
+
+	return event
+}
+
+// trapV1Oid maps the generic and specific trap numbers of an SNMPv1 trap to the equivalent SNMPv2 notification OID
+func trapV1Oid(trap gosnmp.SnmpTrap) string {
+	if trap.GenericTrap >= 0 && trap.GenericTrap < enterpriseSpecificTrap {
+		return genericTrapOids + "." + strconv.Itoa(trap.GenericTrap+1)
+	}
+
+	return strings.TrimPrefix(trap.Enterprise, ".") + ".0." + strconv.Itoa(trap.SpecificTrap)
+}
@@ -0,0 +1,108 @@
+package tasks
+
+import (
+	"as/camscan/internal/camscan/logging"
+	networkApi "as/camscan/internal/camscan/network"
+	"as/camscan/internal/camscan/types"
+	"as/camscan/internal/camscan/types/network"
+	"as/camscan/internal/camscan/workers"
+	"context"
+)
+
+// DiscoveryManager checks every address of every active subnet for access points and subscriber modules using a
+// worker pool.
+type DiscoveryManager struct {
+	appConfig types.AppConfig
+}
+
+func NewDiscoveryManager(appConfig types.AppConfig) *DiscoveryManager {
+	return &DiscoveryManager{
+		appConfig: appConfig,
+	}
+}
+
+// Run checks every address once, blocking until every job has finished or the given context is canceled, and returns
+// the number of discovered devices for each device mode and discovery outcome.
+func (m *DiscoveryManager) Run(ctx context.Context) (bool, network.DiscoverySummary) {
+	summary := network.DiscoverySummary{
+		Outcomes: make(map[string]map[string]int),
+	}
+
+	logging.Info("Setting up discovery jobs for workers...")
+
+	storage := openStorage(m.appConfig.DbConfig)
+
+	if storage == nil {
+		return false, summary
+	}
+
+	// Creates device check jobs for every active subnet
+	success, _, jobs := networkApi.BuildDeviceCheckJobs(storage, m.appConfig, 1)
+
+	if success != true {
+		logging.Error("Failed to build discovery jobs.")
+		return false, summary
+	}
+
+	summary.Addresses = len(jobs)
+
+	pool := workers.NewWithLimits[network.Device, network.DiscoveryResult](m.appConfig.Workers, workers.Limits{
+		PacketsPerSecond: m.appConfig.PacketsPerSecond,
+	})
+	poolCtx, cancel := context.WithCancel(ctx)
+
+	defer cancel()
+
+	go pool.GenerateFrom(poolCtx, jobs)
+	go pool.Run(poolCtx)
+
+	// The results channel is closed once every worker has stopped
+	for result := range pool.Results() {
+		if result.Err != nil {
+			logging.Error("Discovery task failed to execute; id: %s; error: %s;",
+				result.Descriptor.ID, result.Err.Error())
+			continue
+		}
+
+		discoveryResult := result.Value
+
+		if _, ok := summary.Outcomes[discoveryResult.Mode]; !ok {
+			summary.Outcomes[discoveryResult.Mode] = make(map[string]int)
+		}
+
+		summary.Outcomes[discoveryResult.Mode][discoveryResult.Outcome]++
+
+		if discoveryResult.Mode != network.DiscoveryModeUnknown {
+			logging.Debug("Discovered device; mode: %s; outcome: %s; nid: %v; sid: %v; ip: %s;",
+				discoveryResult.Mode, discoveryResult.Outcome, discoveryResult.Device.NetworkId,
+				discoveryResult.Device.SubnetId, discoveryResult.Device.IPv4Address)
+		}
+	}
+
+	if ctx.Err() != nil {
+		logging.Warning("Discovery was canceled; only part of the addresses were checked.")
+	}
+
+	printDiscoverySummary(summary)
+
+	return true, summary
+}
+
+func printDiscoverySummary(summary network.DiscoverySummary) {
+	modes := []string{network.DiscoveryModeAccessPoint, network.DiscoveryModeSubscriberModule}
+	labels := map[string]string{
+		network.DiscoveryModeAccessPoint:      "Access Points",
+		network.DiscoveryModeSubscriberModule: "Subscriber Modules",
+	}
+
+	logging.Info("Discovery finished; addresses: %v; unresponsive: %v; unclassified: %v;",
+		summary.Addresses, summary.Outcomes[network.DiscoveryModeUnknown][network.DiscoveryOutcomeUnresponsive],
+		summary.Outcomes[network.DiscoveryModeUnknown][network.DiscoveryOutcomeUnclassified])
+
+	for _, mode := range modes {
+		outcomes := summary.Outcomes[mode]
+		logging.Info("%s; new: %v; changed: %v; unchanged: %v;", labels[mode],
+			outcomes[network.DiscoveryOutcomeNew], outcomes[network.DiscoveryOutcomeChanged],
+			outcomes[network.DiscoveryOutcomeUnchanged])
+	}
+}
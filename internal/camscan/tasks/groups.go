@@ -0,0 +1,133 @@
+package tasks
+
+import (
+	"as/camscan/internal/camscan/logging"
+	networkApi "as/camscan/internal/camscan/network"
+	"as/camscan/internal/camscan/types/device"
+	"as/camscan/internal/camscan/types/network"
+	"as/camscan/internal/camscan/types/snmp"
+	"fmt"
+	"time"
+)
+
+// accessPointLinksKey is the key of the access point OID map entry that lists the registered subscriber modules, whose
+// MAC addresses are held by the linkMacAddressColumn column
+const accessPointLinksKey = "links"
+const linkMacAddressColumn = "3"
+
+// scanGroup returns the scheduling group of a subscriber module. Subscriber modules that are known to be registered to
+// an access point are grouped by that access point, since they share its sector, while the others are grouped by
+// their subnet.
+func scanGroup(subnets []network.Subnet, registrations map[int]int, record device.SubscriberModule) string {
+	if accessPointId, ok := registrations[record.Id]; ok {
+		return fmt.Sprintf("ap:%v", accessPointId)
+	}
+
+	return subnetGroup(subnets, record.NetworkId, record.IPv4AddressInt)
+}
+
+// subnetGroup returns the most specific active subnet of the given network that contains the given address as a
+// scheduling group. Devices outside every subnet are grouped by their network instead. Subnets of different networks
+// may overlap, so the group always includes the network.
+func subnetGroup(subnets []network.Subnet, networkId int, ipv4 uint32) string {
+	group := fmt.Sprintf("network:%v", networkId)
+	bestMask := -1
+
+	for _, subnet := range subnets {
+		if subnet.NetworkId != networkId || subnet.Status < 1 {
+			continue
+		}
+
+		if subnet.IPv4NetworkMask <= bestMask || subnet.IPv4NetworkMask > 32 {
+			continue
+		}
+
+		mask := ^uint32(0) << (32 - subnet.IPv4NetworkMask)
+
+		if ipv4&mask == subnet.IPv4NetworkAddressInt&mask {
+			group = fmt.Sprintf("subnet:%v:%s/%v", networkId, subnet.IPv4NetworkAddress, subnet.IPv4NetworkMask)
+			bestMask = subnet.IPv4NetworkMask
+		}
+	}
+
+	return group
+}
+
+// updateRegistrations records the given access point as the access point of every known subscriber module in its
+// table of registered subscriber modules. Jobs are grouped by the registrations from the next scan on, since the
+// jobs of the current scan are already queued.
+func (m *TaskManager) updateRegistrations(accessPoint device.AccessPoint, values map[string]interface{}) {
+	links, ok := values[accessPointLinksKey].(snmp.Table)
+
+	if !ok {
+		return
+	}
+
+	updated := int(time.Now().Unix())
+
+	for _, row := range links {
+		cell, ok := row[linkMacAddressColumn].(snmp.Value)
+
+		if !ok {
+			continue
+		}
+
+		found, mac := networkApi.NormalizeMacAddress(cell.SnmpValueChar)
+
+		if !found {
+			continue
+		}
+
+		subscriberModuleId, ok := m.subscriberModuleIds[mac]
+
+		if !ok || m.registrations[subscriberModuleId] == accessPoint.Id {
+			continue
+		}
+
+		logging.Debug("Subscriber module registered to access point; sm: %v; mac: %s; ap: %v; previous ap: %v;",
+			subscriberModuleId, mac, accessPoint.Id, m.registrations[subscriberModuleId])
+
+		m.registrations[subscriberModuleId] = accessPoint.Id
+
+		if m.appConfig.DryRun {
+			continue
+		}
+
+		m.storage.Registrations.UpsertRecord(device.Registration{
+			SubscriberModuleId: subscriberModuleId,
+			AccessPointId:      accessPoint.Id,
+			Updated:            updated,
+		})
+	}
+}
+
+// interleaveJobs reorders the given jobs so that consecutive jobs belong to different groups wherever possible, taking
+// one job of every group in turn. This keeps the workers busy with other groups instead of waiting for a group that
+// has reached its concurrency limit.
+func interleaveJobs(jobs []scanJob) []scanJob {
+	order := make([]string, 0)
+	groups := make(map[string][]scanJob)
+
+	for _, job := range jobs {
+		if _, ok := groups[job.Descriptor.Group]; !ok {
+			order = append(order, job.Descriptor.Group)
+		}
+
+		groups[job.Descriptor.Group] = append(groups[job.Descriptor.Group], job)
+	}
+
+	interleaved := make([]scanJob, 0, len(jobs))
+
+	for len(interleaved) < len(jobs) {
+		for _, group := range order {
+			if len(groups[group]) == 0 {
+				continue
+			}
+
+			interleaved = append(interleaved, groups[group][0])
+			groups[group] = groups[group][1:]
+		}
+	}
+
+	return interleaved
+}
@@ -0,0 +1,82 @@
+package tasks
+
+import (
+	"as/camscan/internal/camscan/types"
+	"as/camscan/internal/camscan/types/device"
+	"as/camscan/internal/camscan/types/network"
+	"as/camscan/internal/camscan/types/snmp"
+	"testing"
+)
+
+func TestScanGroup(t *testing.T) {
+	subnets := []network.Subnet{
+		{NetworkId: 1, IPv4NetworkAddress: "10.0.0.0", IPv4NetworkAddressInt: 0x0a000000, IPv4NetworkMask: 16, Status: 1},
+		{NetworkId: 1, IPv4NetworkAddress: "10.0.1.0", IPv4NetworkAddressInt: 0x0a000100, IPv4NetworkMask: 24, Status: 1},
+		{NetworkId: 1, IPv4NetworkAddress: "10.0.2.0", IPv4NetworkAddressInt: 0x0a000200, IPv4NetworkMask: 24, Status: 0},
+		{NetworkId: 2, IPv4NetworkAddress: "10.0.3.0", IPv4NetworkAddressInt: 0x0a000300, IPv4NetworkMask: 24, Status: 1},
+	}
+	registrations := map[int]int{7: 3}
+
+	tests := []struct {
+		name   string
+		record device.SubscriberModule
+		group  string
+	}{
+		{
+			name:   "registered to an access point",
+			record: device.SubscriberModule{Id: 7, NetworkId: 1, IPv4AddressInt: 0x0a000105},
+			group:  "ap:3",
+		},
+		{
+			name:   "most specific subnet",
+			record: device.SubscriberModule{Id: 8, NetworkId: 1, IPv4AddressInt: 0x0a000105},
+			group:  "subnet:1:10.0.1.0/24",
+		},
+		{
+			name:   "inactive subnet",
+			record: device.SubscriberModule{Id: 8, NetworkId: 1, IPv4AddressInt: 0x0a000205},
+			group:  "subnet:1:10.0.0.0/16",
+		},
+		{
+			name:   "subnet of another network",
+			record: device.SubscriberModule{Id: 8, NetworkId: 1, IPv4AddressInt: 0x0a000305},
+			group:  "subnet:1:10.0.0.0/16",
+		},
+		{
+			name:   "overlapping address of another network",
+			record: device.SubscriberModule{Id: 8, NetworkId: 2, IPv4AddressInt: 0x0a000105},
+			group:  "network:2",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if group := scanGroup(subnets, registrations, test.record); group != test.group {
+				t.Errorf("group = %s, want %s", group, test.group)
+			}
+		})
+	}
+}
+
+func TestUpdateRegistrations(t *testing.T) {
+	m := &TaskManager{
+		appConfig:           types.AppConfig{DryRun: true},
+		subscriberModuleIds: map[string]int{"0a003eaabbcc": 7, "0a003eaabbdd": 8},
+		registrations:       map[int]int{8: 2},
+	}
+
+	values := map[string]interface{}{
+		accessPointLinksKey: snmp.Table{
+			"1": snmp.TableRow{linkMacAddressColumn: snmp.Value{SnmpValueChar: "0a:00:3e:aa:bb:cc"}},
+			"2": snmp.TableRow{linkMacAddressColumn: snmp.Value{SnmpValueChar: "0a:00:3e:aa:bb:dd"}},
+			"3": snmp.TableRow{linkMacAddressColumn: snmp.Value{SnmpValueChar: "0a:00:3e:aa:bb:ee"}},
+			"4": snmp.TableRow{"2": snmp.Value{SnmpValueChar: "0a:00:3e:aa:bb:ff"}},
+		},
+	}
+
+	m.updateRegistrations(device.AccessPoint{Id: 3}, values)
+
+	if len(m.registrations) != 2 || m.registrations[7] != 3 || m.registrations[8] != 3 {
+		t.Errorf("registrations = %v, want both subscriber modules registered to access point 3", m.registrations)
+	}
+}
@@ -0,0 +1,73 @@
+package tasks
+
+import (
+	"as/camscan/internal/camscan/logging"
+	"as/camscan/internal/camscan/mib"
+	"as/camscan/internal/camscan/types/snmp"
+	"fmt"
+)
+
+// LoadMibs parses the MIB files of the configured MIB directory, which the symbolic OIDs of the OID map are resolved
+// with and enumerated values are labelled with
+func (m *TaskManager) LoadMibs() bool {
+	success, tree := loadMibs(m.appConfig.MibDir)
+
+	if success != true {
+		return false
+	}
+
+	m.mibs = tree
+
+	return true
+}
+
+func loadMibs(mibDir string) (bool, *mib.Tree) {
+	tree, err := mib.Load(mibDir)
+
+	if err != nil {
+		logging.Error("Failed to load MIB files; path: %s; error: %s;", mibDir, err.Error())
+		return false, nil
+	}
+
+	return true, tree
+}
+
+// resolveOidMaps replaces the symbolic OIDs of the given OID map entries, like "WHISP-SM-MIB::jitter.0", with numeric
+// OIDs. Every entry that can't be resolved is logged and false is returned.
+func resolveOidMaps(mibs *mib.Tree, oidMaps []snmp.OidMap) bool {
+	valid := true
+
+	for i, om := range oidMaps {
+		oid, err := mibs.Resolve(om.Oid)
+
+		if err != nil {
+			logging.Error("Invalid OID in SNMP OID map; type: %v; key: %s; oid: %s; error: %s;",
+				om.DeviceType, om.KeyName, om.Oid, err.Error())
+			valid = false
+			continue
+		}
+
+		if oid != om.Oid {
+			logging.Trace1("Resolved SNMP OID map entry; type: %v; key: %s; name: %s; oid: %s;",
+				om.DeviceType, om.KeyName, om.Oid, oid)
+		}
+
+		oidMaps[i].Oid = oid
+	}
+
+	return valid
+}
+
+// formatValue renders a collected value for the exports, labelling enumerated integers with the label that the MIB
+// object of the given OID defines, unless the value was already labelled by its transform
+func formatValue(mibs *mib.Tree, oid string, result interface{}) string {
+	value, ok := result.(snmp.Value)
+
+	if ok && mibs != nil && value.SnmpType == snmp.TypeInteger && value.SnmpValueChar == "" {
+		if label, ok := mibs.Label(oid, int(value.SnmpValueNum)); ok {
+			return label
+		}
+	}
+
+	return fmt.Sprintf("%v", result)
+}
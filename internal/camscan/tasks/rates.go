@@ -0,0 +1,150 @@
+package tasks
+
+import (
+	"as/camscan/internal/camscan/logging"
+	snmpApi "as/camscan/internal/camscan/snmp"
+	"as/camscan/internal/camscan/types/snmp"
+	"time"
+)
+
+// counterWrap is the value at which a Counter32 wraps back to zero
+const counterWrap = 1 << 32
+
+// bootSlack is the number of seconds a device's last boot, derived from its uptime, may drift between polls before
+// the device is considered to have rebooted
+const bootSlack = 2
+
+// counterKey identifies a single counter of a single device
+type counterKey struct {
+	DeviceType int
+	DeviceId   int
+	OidMapId   int
+	OidIndex   string
+}
+
+// deviceRates holds the rates computed for a device keyed by key name and OID index, where scalar OIDs have an empty
+// index and table cells are indexed by column and row
+type deviceRates map[string]map[string]float64
+
+// loadCounterSamples loads, on the first scan, the latest recently stored sample of every counter so that rates can be
+// computed from the very first poll. The samples are kept in memory between the scans of the same manager.
+func (m *TaskManager) loadCounterSamples() bool {
+	if m.counterSamples != nil {
+		return true
+	}
+
+	m.counterSamples = make(map[counterKey]snmp.Value)
+
+	if m.appConfig.DryRun {
+		return true
+	}
+
+	since := m.resumeSince(int(time.Now().Unix()))
+
+	for _, deviceType := range []int{snmp.DeviceTypeAccessPoint, snmp.DeviceTypeSubscriberModule} {
+		success, records := m.storage.Values.GetLatestCounterRecords(deviceType, since)
+
+		if success != true {
+			return false
+		}
+
+		for _, record := range records {
+			key := counterKey{DeviceType: deviceType, DeviceId: record.DeviceId, OidMapId: record.OidMapId,
+				OidIndex: record.OidIndex}
+			m.counterSamples[key] = record
+		}
+	}
+
+	logging.Debug("Loaded %v SNMP counter samples.", len(m.counterSamples))
+
+	return true
+}
+
+// deviceLastBoot returns the unix timestamp that the device last booted at, if its sysUpTime was polled
+func deviceLastBoot(oidMaps []snmp.OidMap, values map[string]interface{}) (int, bool) {
+	for _, om := range oidMaps {
+		if om.Oid != snmpApi.SysUpTimeOid {
+			continue
+		}
+
+		value, ok := values[om.KeyName].(snmp.Value)
+
+		if !ok || value.SnmpType != snmp.TypeTimeTicks {
+			continue
+		}
+
+		return value.LastBoot, true
+	}
+
+	return 0, false
+}
+
+// computeRate computes the per-second rate of the given counter sample since the previous sample of the same counter,
+// and keeps the sample for the next poll. No rate is computed for the first sample of a counter, nor when the counter
+// was reset because the device rebooted in between. A Counter32 sample lower than the previous one is considered to
+// have wrapped past 2^32 multiplied by scale, the factor of the OID map entry's transform, but only when the device's
+// last boot shows it didn't reboot. Without it, a wrap can't be told apart from a reset, so the sample only becomes the
+// baseline of the next rate. Counter64 values aren't expected to wrap, so a lower sample is always considered a reset.
+func (m *TaskManager) computeRate(sample snmp.Value, scale float64, lastBoot int, hasLastBoot bool) (snmp.Rate, bool) {
+	key := counterKey{DeviceType: sample.DeviceType, DeviceId: sample.DeviceId, OidMapId: sample.OidMapId,
+		OidIndex: sample.OidIndex}
+	previous, ok := m.counterSamples[key]
+	m.counterSamples[key] = sample
+
+	if !ok || previous.SnmpType != sample.SnmpType {
+		return snmp.Rate{}, false
+	}
+
+	period := sample.Captured - previous.Captured
+
+	if period <= 0 {
+		return snmp.Rate{}, false
+	}
+
+	// The device booted after the previous sample was captured, so its counters started over
+	if hasLastBoot && lastBoot > previous.Captured+bootSlack {
+		logging.Trace1("Counter reset by device reboot; type: %v; did: %v; om: %v; index: %s;",
+			sample.DeviceType, sample.DeviceId, sample.OidMapId, sample.OidIndex)
+		return snmp.Rate{}, false
+	}
+
+	delta := sample.SnmpValueNum - previous.SnmpValueNum
+
+	if delta < 0 {
+		if sample.SnmpType != snmp.TypeCounter32 || !hasLastBoot {
+			logging.Trace1("Counter dropped without a known wrap; type: %v; did: %v; om: %v; index: %s;",
+				sample.DeviceType, sample.DeviceId, sample.OidMapId, sample.OidIndex)
+			return snmp.Rate{}, false
+		}
+
+		delta += counterWrap * scale
+	}
+
+	return snmp.Rate{
+		ScanId:     sample.ScanId,
+		DeviceType: sample.DeviceType,
+		DeviceId:   sample.DeviceId,
+		OidMapId:   sample.OidMapId,
+		OidIndex:   sample.OidIndex,
+		Rate:       delta / float64(period),
+		Period:     period,
+		Captured:   sample.Captured,
+	}, true
+}
+
+// queueRate computes the rate of the given counter sample of the given OID map entry, if possible, then queues it for
+// storage and adds it to the rates of the device
+func (m *TaskManager) queueRate(rates deviceRates, om snmp.OidMap, sample snmp.Value, lastBoot int, hasLastBoot bool) {
+	rate, ok := m.computeRate(sample, om.Transform.Factor(), lastBoot, hasLastBoot)
+
+	if !ok {
+		return
+	}
+
+	if _, ok := rates[om.KeyName]; !ok {
+		rates[om.KeyName] = make(map[string]float64)
+	}
+
+	rates[om.KeyName][sample.OidIndex] = rate.Rate
+	m.pendingRates = append(m.pendingRates, rate)
+}
@@ -0,0 +1,174 @@
+package tasks
+
+import (
+	"as/camscan/internal/camscan/types/snmp"
+	"math"
+	"testing"
+)
+
+func counterSample(snmpType int, value float64, captured int) snmp.Value {
+	return snmp.Value{
+		DeviceType:   snmp.DeviceTypeAccessPoint,
+		DeviceId:     1,
+		OidMapId:     2,
+		OidIndex:     "10.1",
+		SnmpType:     snmpType,
+		SnmpValueNum: value,
+		Captured:     captured,
+	}
+}
+
+func TestComputeRate(t *testing.T) {
+	tests := []struct {
+		name        string
+		previous    snmp.Value
+		sample      snmp.Value
+		scale       float64
+		lastBoot    int
+		hasLastBoot bool
+		rate        float64
+		ok          bool
+	}{
+		{
+			name:   "first sample",
+			sample: counterSample(snmp.TypeCounter32, 1000, 1300),
+			scale:  1,
+		},
+		{
+			name:     "normal increase",
+			previous: counterSample(snmp.TypeCounter32, 1000, 1000),
+			sample:   counterSample(snmp.TypeCounter32, 4000, 1300),
+			scale:    1,
+			rate:     10,
+			ok:       true,
+		},
+		{
+			name:     "normal increase without uptime",
+			previous: counterSample(snmp.TypeCounter32, 1000, 1000),
+			sample:   counterSample(snmp.TypeCounter32, 1600, 1300),
+			scale:    1,
+			rate:     2,
+			ok:       true,
+		},
+		{
+			name:        "32-bit wrap",
+			previous:    counterSample(snmp.TypeCounter32, math.MaxUint32-999, 1000),
+			sample:      counterSample(snmp.TypeCounter32, 2000, 1300),
+			scale:       1,
+			lastBoot:    500,
+			hasLastBoot: true,
+			rate:        10,
+			ok:          true,
+		},
+		{
+			name:        "scaled 32-bit wrap",
+			previous:    counterSample(snmp.TypeCounter32, (math.MaxUint32-999)*8, 1000),
+			sample:      counterSample(snmp.TypeCounter32, 2000*8, 1300),
+			scale:       8,
+			lastBoot:    500,
+			hasLastBoot: true,
+			rate:        80,
+			ok:          true,
+		},
+		{
+			name:     "32-bit drop without uptime",
+			previous: counterSample(snmp.TypeCounter32, math.MaxUint32-999, 1000),
+			sample:   counterSample(snmp.TypeCounter32, 2000, 1300),
+			scale:    1,
+		},
+		{
+			name:        "64-bit counter",
+			previous:    counterSample(snmp.TypeCounter64, 1e12, 1000),
+			sample:      counterSample(snmp.TypeCounter64, 1e12+3e6, 1300),
+			scale:       1,
+			lastBoot:    500,
+			hasLastBoot: true,
+			rate:        1e4,
+			ok:          true,
+		},
+		{
+			name:        "64-bit drop",
+			previous:    counterSample(snmp.TypeCounter64, 1e12, 1000),
+			sample:      counterSample(snmp.TypeCounter64, 2000, 1300),
+			scale:       1,
+			lastBoot:    500,
+			hasLastBoot: true,
+		},
+		{
+			name:        "reboot",
+			previous:    counterSample(snmp.TypeCounter32, 1000, 1000),
+			sample:      counterSample(snmp.TypeCounter32, 4000, 1300),
+			scale:       1,
+			lastBoot:    1200,
+			hasLastBoot: true,
+		},
+		{
+			name:        "last boot drifting within the slack",
+			previous:    counterSample(snmp.TypeCounter32, 1000, 1000),
+			sample:      counterSample(snmp.TypeCounter32, 4000, 1300),
+			scale:       1,
+			lastBoot:    1000 + bootSlack,
+			hasLastBoot: true,
+			rate:        10,
+			ok:          true,
+		},
+		{
+			name:     "no time passed",
+			previous: counterSample(snmp.TypeCounter32, 1000, 1300),
+			sample:   counterSample(snmp.TypeCounter32, 4000, 1300),
+			scale:    1,
+		},
+		{
+			name:     "counter type changed",
+			previous: counterSample(snmp.TypeCounter32, 1000, 1000),
+			sample:   counterSample(snmp.TypeCounter64, 4000, 1300),
+			scale:    1,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			m := &TaskManager{counterSamples: make(map[counterKey]snmp.Value)}
+
+			if test.previous != (snmp.Value{}) {
+				m.computeRate(test.previous, test.scale, 0, false)
+			}
+
+			rate, ok := m.computeRate(test.sample, test.scale, test.lastBoot, test.hasLastBoot)
+
+			if ok != test.ok {
+				t.Fatalf("ok = %v, want %v", ok, test.ok)
+			}
+
+			period := test.sample.Captured - test.previous.Captured
+
+			if ok && (math.Abs(rate.Rate-test.rate) > 1e-9 || rate.Period != period) {
+				t.Errorf("rate = %v per %vs, want %v per %vs", rate.Rate, rate.Period, test.rate, period)
+			}
+
+			// Every sample becomes the baseline of the next rate, whether or not a rate was computed
+			key := counterKey{DeviceType: test.sample.DeviceType, DeviceId: test.sample.DeviceId,
+				OidMapId: test.sample.OidMapId, OidIndex: test.sample.OidIndex}
+
+			if m.counterSamples[key] != test.sample {
+				t.Errorf("baseline = %v, want %v", m.counterSamples[key], test.sample)
+			}
+		})
+	}
+}
+
+func TestComputeRateRebaselinesAfterDrop(t *testing.T) {
+	m := &TaskManager{counterSamples: make(map[counterKey]snmp.Value)}
+
+	m.computeRate(counterSample(snmp.TypeCounter32, 5000, 1000), 1, 0, false)
+
+	if _, ok := m.computeRate(counterSample(snmp.TypeCounter32, 100, 1300), 1, 0, false); ok {
+		t.Fatal("rate computed for a drop without uptime")
+	}
+
+	rate, ok := m.computeRate(counterSample(snmp.TypeCounter32, 3100, 1600), 1, 0, false)
+
+	if !ok || rate.Rate != 10 || rate.Period != 300 {
+		t.Errorf("rate = %v per %vs, ok = %v, want 10 per 300s", rate.Rate, rate.Period, ok)
+	}
+}
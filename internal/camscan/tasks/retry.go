@@ -0,0 +1,98 @@
+package tasks
+
+import (
+	"as/camscan/internal/camscan/logging"
+	snmpApi "as/camscan/internal/camscan/snmp"
+	"as/camscan/internal/camscan/types/snmp"
+	"as/camscan/internal/camscan/workers"
+	"context"
+	"errors"
+	"time"
+)
+
+// failureReason classifies the error a device job failed with as one of the snmp.Failure* reasons
+func failureReason(err error) string {
+	var pollError *snmpApi.PollError
+	var timeoutError *workers.TimeoutError
+
+	if errors.As(err, &pollError) {
+		return pollError.Reason
+	}
+
+	if errors.As(err, &timeoutError) {
+		return snmp.FailureTimeout
+	}
+
+	return snmp.FailureError
+}
+
+// isRetryable determines whether a job that failed for the given reason may succeed when retried. Devices that reject
+// every credential keep doing so, and unexpected errors like panics are likely to repeat.
+func isRetryable(reason string) bool {
+	return reason == snmp.FailureUnreachable || reason == snmp.FailureTimeout || reason == snmp.FailurePartial
+}
+
+// retryJob submits the given job to the pool again once its backoff has passed, unless the job has no attempts left or
+// the error it failed with won't be resolved by retrying. Returns whether the job will be retried.
+func (m *TaskManager) retryJob(ctx context.Context, pool scanPool, job scanJob, err error) bool {
+	if ctx.Err() != nil || job.Descriptor.Retries >= m.appConfig.RetryAttempts || !isRetryable(failureReason(err)) {
+		return false
+	}
+
+	delay := m.retryDelay(job.Descriptor.Retries)
+	job.Descriptor.Retries++
+
+	logging.Debug("Retrying job; id: %s; retry: %v; delay: %v; error: %s;",
+		job.Descriptor.ID, job.Descriptor.Retries, delay, err.Error())
+
+	go func() {
+		select {
+		case <-time.After(delay):
+			pool.Submit(ctx, job)
+		case <-ctx.Done():
+		}
+	}()
+
+	return true
+}
+
+// retryDelay returns the backoff before the retry of a job that was already retried the given number of times, which
+// doubles with every retry but never exceeds the scan interval
+func (m *TaskManager) retryDelay(retries int) time.Duration {
+	delay := m.appConfig.RetryBackoff
+	limit := m.appConfig.ScanInterval
+
+	for i := 0; i < retries; i++ {
+		// Stop doubling before the delay could overflow
+		if delay >= limit/2 {
+			return limit
+		}
+
+		delay *= 2
+	}
+
+	if delay > limit {
+		return limit
+	}
+
+	return delay
+}
+
+// recordFailure logs the final failure of a device job and queues it for storage along with the scan's values
+func (m *TaskManager) recordFailure(deviceType int, deviceId int, host string, result scanResult) {
+	reason := failureReason(result.Err)
+	attempts := result.Descriptor.Retries + 1
+
+	logging.Warning("Failed to poll device; type: %v; id: %v; ip: %s; reason: %s; attempts: %v; error: %s;",
+		deviceType, deviceId, host, reason, attempts, result.Err.Error())
+
+	m.failures = append(m.failures, snmp.PollFailure{
+		ScanId:     m.scan.Id,
+		DeviceType: deviceType,
+		DeviceId:   deviceId,
+		Reason:     reason,
+		Attempts:   attempts,
+		Message:    result.Err.Error(),
+		Recorded:   int(time.Now().Unix()),
+	})
+}
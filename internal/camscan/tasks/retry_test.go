@@ -0,0 +1,33 @@
+package tasks
+
+import (
+	"as/camscan/internal/camscan/types"
+	"testing"
+	"time"
+)
+
+func TestRetryDelay(t *testing.T) {
+	tests := []struct {
+		name     string
+		backoff  time.Duration
+		interval time.Duration
+		retries  int
+		delay    time.Duration
+	}{
+		{name: "first retry", backoff: 5 * time.Second, interval: 5 * time.Minute, retries: 0, delay: 5 * time.Second},
+		{name: "doubled", backoff: 5 * time.Second, interval: 5 * time.Minute, retries: 3, delay: 40 * time.Second},
+		{name: "capped", backoff: 5 * time.Second, interval: 5 * time.Minute, retries: 6, delay: 5 * time.Minute},
+		{name: "no overflow", backoff: 5 * time.Second, interval: 5 * time.Minute, retries: 70, delay: 5 * time.Minute},
+		{name: "backoff above interval", backoff: time.Hour, interval: 5 * time.Minute, retries: 0, delay: 5 * time.Minute},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			m := &TaskManager{appConfig: types.AppConfig{RetryBackoff: test.backoff, ScanInterval: test.interval}}
+
+			if delay := m.retryDelay(test.retries); delay != test.delay {
+				t.Errorf("delay = %v, want %v", delay, test.delay)
+			}
+		})
+	}
+}
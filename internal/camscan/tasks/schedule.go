@@ -0,0 +1,140 @@
+package tasks
+
+import (
+	"as/camscan/internal/camscan/logging"
+	"as/camscan/internal/camscan/types/snmp"
+	"time"
+)
+
+// pollKey identifies a poll group of a single device
+type pollKey struct {
+	DeviceType int
+	DeviceId   int
+	PollGroup  string
+}
+
+// loadPollSchedule loads the poll groups and, on the first scan, when every poll group of every device was last polled
+// according to the recently stored values. Poll groups without recent values are due anyway. The poll times are kept
+// in memory between the scans of the same manager.
+func (m *TaskManager) loadPollSchedule() bool {
+	success, records := m.storage.PollGroups.GetRecords()
+
+	if success != true {
+		return false
+	}
+
+	m.pollGroups = make(map[string]snmp.PollGroup)
+
+	for _, record := range records {
+		m.pollGroups[record.Name] = record
+	}
+
+	if m.lastPolled != nil {
+		return true
+	}
+
+	m.lastPolled = make(map[pollKey]int)
+
+	if m.appConfig.DryRun {
+		return true
+	}
+
+	oidMaps := map[int][]snmp.OidMap{
+		snmp.DeviceTypeAccessPoint:      m.accessPointOidMaps,
+		snmp.DeviceTypeSubscriberModule: m.subscriberModuleOidMaps,
+	}
+
+	since := m.resumeSince(int(time.Now().Unix()))
+
+	for deviceType, deviceOidMaps := range oidMaps {
+		groups := make(map[int]string)
+
+		for _, om := range deviceOidMaps {
+			groups[om.Id] = om.PollGroup
+		}
+
+		success, captures := m.storage.Values.GetCaptureTimes(deviceType, since)
+
+		if success != true {
+			return false
+		}
+
+		for _, capture := range captures {
+			group, ok := groups[capture.OidMapId]
+
+			if !ok || group == "" {
+				continue
+			}
+
+			key := pollKey{DeviceType: deviceType, DeviceId: capture.DeviceId, PollGroup: group}
+
+			if capture.Captured > m.lastPolled[key] {
+				m.lastPolled[key] = capture.Captured
+			}
+		}
+	}
+
+	return true
+}
+
+// resumeSince returns the unix timestamp from which the values stored by earlier runs are loaded to resume the poll
+// schedule and counter rates. Every poll group is polled at least once per its interval or per scan cycle, whichever
+// is longer, so older values only belong to devices and OIDs that are no longer polled. Twice that span leaves room
+// for a missed cycle.
+func (m *TaskManager) resumeSince(now int) int {
+	span := int(m.appConfig.ScanInterval.Seconds())
+
+	for _, group := range m.pollGroups {
+		if group.Interval > span {
+			span = group.Interval
+		}
+	}
+
+	return now - 2*span
+}
+
+// dueOidMaps selects the OID map entries whose poll group is due for the given device along with the names of the due
+// poll groups. Entries without a poll group, or whose poll group isn't configured, are due every cycle. A poll group is
+// considered due when its interval will have elapsed by the middle of the next cycle, so that it's polled by the cycle
+// closest to its interval rather than the first one after it.
+func (m *TaskManager) dueOidMaps(deviceType int, deviceId int, oidMaps []snmp.OidMap, now int) ([]snmp.OidMap, []string) {
+	due := make([]snmp.OidMap, 0, len(oidMaps))
+	dueGroups := make([]string, 0)
+	checked := make(map[string]bool)
+	slack := int(m.appConfig.ScanInterval.Seconds() / 2)
+
+	for _, om := range oidMaps {
+		if om.PollGroup == "" {
+			due = append(due, om)
+			continue
+		}
+
+		isDue, ok := checked[om.PollGroup]
+
+		if !ok {
+			group := m.pollGroups[om.PollGroup]
+			polled := m.lastPolled[pollKey{DeviceType: deviceType, DeviceId: deviceId, PollGroup: om.PollGroup}]
+			isDue = group.Interval <= 0 || now-polled+slack >= group.Interval
+			checked[om.PollGroup] = isDue
+
+			if isDue {
+				dueGroups = append(dueGroups, om.PollGroup)
+			}
+		}
+
+		if isDue {
+			due = append(due, om)
+		}
+	}
+
+	return due, dueGroups
+}
+
+// markPolled records that the given poll groups of the given device were polled at the given time
+func (m *TaskManager) markPolled(deviceType int, deviceId int, groups []string, polled int) {
+	for _, group := range groups {
+		m.lastPolled[pollKey{DeviceType: deviceType, DeviceId: deviceId, PollGroup: group}] = polled
+	}
+
+	logging.Trace1("Marked poll groups as polled; type: %v; did: %v; groups: %v;", deviceType, deviceId, groups)
+}
@@ -0,0 +1,51 @@
+package tasks
+
+import (
+	"as/camscan/internal/camscan/types"
+	"as/camscan/internal/camscan/types/snmp"
+	"testing"
+	"time"
+)
+
+func TestResumeSince(t *testing.T) {
+	tests := []struct {
+		name       string
+		interval   time.Duration
+		pollGroups map[string]snmp.PollGroup
+		since      int
+	}{
+		{
+			name:     "scan interval only",
+			interval: 5 * time.Minute,
+			since:    100000 - 600,
+		},
+		{
+			name:     "longest poll group interval",
+			interval: 5 * time.Minute,
+			pollGroups: map[string]snmp.PollGroup{
+				"status":     {Name: "status"},
+				"interfaces": {Name: "interfaces", Interval: 900},
+				"inventory":  {Name: "inventory", Interval: 3600},
+			},
+			since: 100000 - 7200,
+		},
+		{
+			name:     "scan interval longer than every poll group",
+			interval: 2 * time.Hour,
+			pollGroups: map[string]snmp.PollGroup{
+				"inventory": {Name: "inventory", Interval: 3600},
+			},
+			since: 100000 - 14400,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			m := &TaskManager{appConfig: types.AppConfig{ScanInterval: test.interval}, pollGroups: test.pollGroups}
+
+			if since := m.resumeSince(100000); since != test.since {
+				t.Errorf("since = %v, want %v", since, test.since)
+			}
+		})
+	}
+}
@@ -0,0 +1,174 @@
+package tasks
+
+import (
+	"as/camscan/internal/camscan/database"
+	"as/camscan/internal/camscan/database/repository"
+	"as/camscan/internal/camscan/logging"
+	"as/camscan/internal/camscan/types"
+	"as/camscan/internal/camscan/types/snmp"
+	"time"
+)
+
+// openStorage returns the repositories of the CamScan database connection, connecting to the database when there is no
+// connection yet so that consecutive scans reuse the same connection.
+func openStorage(dbConfig types.DbConfig) *repository.Repositories {
+	if database.HasConnection(database.ConnectionMap.CamScan) == false {
+		success, _ := database.CreateConnection(database.ConnectionMap.CamScan, dbConfig)
+
+		if success != true {
+			return nil
+		}
+	}
+
+	return database.GetRepositories(database.ConnectionMap.CamScan)
+}
+
+// startScan creates the scan run that collected values are stored under
+func (m *TaskManager) startScan() bool {
+	m.scan = snmp.Scan{
+		Started: int(time.Now().Unix()),
+		Status:  snmp.ScanStatusRunning,
+	}
+
+	if m.appConfig.DryRun {
+		return true
+	}
+
+	success, record := m.storage.Scans.InsertRecord(m.scan)
+
+	if success != true {
+		return false
+	}
+
+	m.scan = record
+
+	logging.Debug("Started SNMP scan; id: %v;", m.scan.Id)
+
+	return true
+}
+
+func (m *TaskManager) finishScan(status int) bool {
+	success := m.flushValues(true)
+
+	m.scan.Finished = int(time.Now().Unix())
+	m.scan.Status = status
+
+	if m.appConfig.DryRun || m.scan.Id == 0 {
+		return success
+	}
+
+	if updated, _ := m.storage.Scans.UpdateRecord(m.scan); !updated {
+		return false
+	}
+
+	if !m.storage.Failures.InsertRecords(m.failures) {
+		return false
+	}
+
+	logging.Debug("Stored %v SNMP values and %v rates for scan; id: %v;", m.storedValues, m.storedRates, m.scan.Id)
+
+	return success
+}
+
+// queueDeviceValues converts the values collected from a device into value records and queues them for storage along
+// with the rates of its counters, which are also returned
+func (m *TaskManager) queueDeviceValues(deviceType int, deviceId int, oidMaps []snmp.OidMap,
+	values map[string]interface{}) deviceRates {
+	captured := int(time.Now().Unix())
+	rates := make(deviceRates)
+	lastBoot, hasLastBoot := deviceLastBoot(oidMaps, values)
+
+	for _, om := range oidMaps {
+		result, ok := values[om.KeyName]
+
+		if !ok {
+			continue
+		}
+
+		record := snmp.Value{
+			ScanId:     m.scan.Id,
+			DeviceType: deviceType,
+			DeviceId:   deviceId,
+			OidMapId:   om.Id,
+			Captured:   captured,
+		}
+
+		switch value := result.(type) {
+		case snmp.Value:
+			record.SnmpType = value.SnmpType
+			record.SnmpValueChar = value.SnmpValueChar
+			record.SnmpValueNum = value.SnmpValueNum
+			record.SnmpValueText = value.SnmpValueText
+			record.Unit = value.Unit
+			record.LastBoot = value.LastBoot
+			m.pendingValues = append(m.pendingValues, record)
+
+			if record.IsCounter() {
+				m.queueRate(rates, om, record, lastBoot, hasLastBoot)
+			}
+		case snmp.Table:
+			// Table values are stored with the column and row index that follow the table entry OID
+			for index, row := range value {
+				for column, cell := range row {
+					cellValue, ok := cell.(snmp.Value)
+
+					if !ok {
+						continue
+					}
+
+					record.OidIndex = column + "." + index
+					record.SnmpType = cellValue.SnmpType
+					record.SnmpValueChar = cellValue.SnmpValueChar
+					record.SnmpValueNum = cellValue.SnmpValueNum
+					record.SnmpValueText = cellValue.SnmpValueText
+					record.Unit = cellValue.Unit
+					record.LastBoot = cellValue.LastBoot
+					m.pendingValues = append(m.pendingValues, record)
+
+					if record.IsCounter() {
+						m.queueRate(rates, om, record, lastBoot, hasLastBoot)
+					}
+				}
+			}
+		}
+	}
+
+	m.flushValues(false)
+
+	return rates
+}
+
+// flushValues stores the queued values and rates once a full batch of values is waiting, or regardless of the batch
+// size when forced
+func (m *TaskManager) flushValues(force bool) bool {
+	batchSize := m.appConfig.DbConfig.BatchSize
+
+	if len(m.pendingValues) == 0 || (!force && len(m.pendingValues) < batchSize) {
+		return true
+	}
+
+	if m.appConfig.DryRun || m.scan.Id == 0 {
+		m.pendingValues = m.pendingValues[:0]
+		m.pendingRates = m.pendingRates[:0]
+		return true
+	}
+
+	success := m.storage.Values.InsertRecords(m.pendingValues, batchSize)
+
+	if success {
+		m.storedValues += len(m.pendingValues)
+	}
+
+	if len(m.pendingRates) > 0 {
+		if m.storage.Rates.InsertRecords(m.pendingRates, batchSize) {
+			m.storedRates += len(m.pendingRates)
+		} else {
+			success = false
+		}
+	}
+
+	m.pendingValues = m.pendingValues[:0]
+	m.pendingRates = m.pendingRates[:0]
+
+	return success
+}
@@ -1,15 +1,12 @@
 package tasks
 
 import (
-	"as/camscan/internal/camscan/config"
-	"as/camscan/internal/camscan/database"
-	dbAp "as/camscan/internal/camscan/database/device/ap"
-	dbSm "as/camscan/internal/camscan/database/device/sm"
-	dbSubnet "as/camscan/internal/camscan/database/network/subnet"
-	dbOm "as/camscan/internal/camscan/database/snmp/om"
+	"as/camscan/internal/camscan/database/repository"
 	"as/camscan/internal/camscan/device/ap"
 	"as/camscan/internal/camscan/device/sm"
 	"as/camscan/internal/camscan/logging"
+	"as/camscan/internal/camscan/mib"
+	"as/camscan/internal/camscan/types"
 	"as/camscan/internal/camscan/types/device"
 	"as/camscan/internal/camscan/types/network"
 	"as/camscan/internal/camscan/types/snmp"
@@ -18,160 +15,301 @@ import (
 	"encoding/csv"
 	"fmt"
 	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
 )
 
-var accessPointOidMaps []snmp.OidMap
-var accessPointOids map[string]string
-var accessPointResults []map[string]interface{}
-var accessPoints []device.AccessPoint
-var subscriberModuleOidMaps []snmp.OidMap
-var subscriberModuleOids map[string]string
-var subscriberModuleResults []map[string]interface{}
-var subscriberModules []device.SubscriberModule
-var subnets []network.Subnet
-
-// Define worker pool variables
-var jobs = make([]workers.Job, 0)
-var ctx context.Context
-var wp workers.WorkerPool
-
-var accessPointCSV = ""
-var subscriberModuleCSV = ""
-
-func ManageTasks() bool {
-	select {
-	case <-ctx.Done():
-		// Handles the case where the context is canceled before the first task is executed
-		//logging.Error("Context canceled before the first task was executed; error: %s;", ctx.Err().Error())
-	case r, ok := <-wp.Results():
-		// Handles the case where a task has finished executing
-		if !ok {
-			break
-		}
+// TaskManager polls every access point and subscriber module in the inventory using a worker pool. A manager can run
+// any number of scans one after the other, e.g. in daemon mode, and keeps track of when each poll group of each
+// device was last polled between them.
+type TaskManager struct {
+	appConfig               types.AppConfig
+	storage                 *repository.Repositories
+	accessPoints            []device.AccessPoint
+	accessPointOidMaps      []snmp.OidMap
+	accessPointResults      []deviceResult
+	accessPointTables       map[string]string
+	subscriberModules       []device.SubscriberModule
+	subscriberModuleIds     map[string]int
+	registrations           map[int]int
+	subnets                 []network.Subnet
+	subscriberModuleOidMaps []snmp.OidMap
+	subscriberModuleResults []deviceResult
+	subscriberModuleTables  map[string]string
+	scan                    snmp.Scan
+	pendingValues           []snmp.Value
+	failures                []snmp.PollFailure
+	pendingRates            []snmp.Rate
+	storedValues            int
+	storedRates             int
+	pollGroups              map[string]snmp.PollGroup
+	lastPolled              map[pollKey]int
+	counterSamples          map[counterKey]snmp.Value
+	mibs                    *mib.Tree
+}
 
-		if r.Err != nil {
-			logging.Error("Task failed to execute; id: %s; error: %s;", r.Descriptor.ID, r.Err.Error())
-		}
+// scanJob polls the due OIDs of a single device, producing the collected values keyed by key name
+type scanJob = workers.Job[snmp.PollRequest, map[string]interface{}]
+type scanPool = workers.WorkerPool[snmp.PollRequest, map[string]interface{}]
+type scanResult = workers.Result[map[string]interface{}]
+
+// deviceResult associates the values collected from a device, and the rates of its counters, with the device they
+// were collected from
+type deviceResult struct {
+	DeviceId    int
+	IPv4Address string
+	Values      map[string]interface{}
+	Rates       deviceRates
+}
 
-		//logging.Debug("Task finished executing; id: %s;", r.Descriptor.ID)
+func NewTaskManager(appConfig types.AppConfig) *TaskManager {
+	return &TaskManager{
+		appConfig:     appConfig,
+		pendingValues: make([]snmp.Value, 0),
+		pollGroups:    make(map[string]snmp.PollGroup),
+	}
+}
 
-		// Process the task result data
-		deviceResult := r.Value.(map[string]interface{})
+// Run executes a single scan of every device whose poll groups are due, blocking until every job has finished or the
+// given context is canceled. Jobs that fail for reasons that may be temporary are retried with a backoff, and the
+// reason of every job that still fails is recorded. When canceled, the jobs being executed are allowed to finish while
+// the remaining jobs and retries are skipped. The collected values are exported and stored before the scan's summary
+// is returned.
+func (m *TaskManager) Run(ctx context.Context) (bool, snmp.ScanSummary) {
+	summary := snmp.ScanSummary{
+		Started: int(time.Now().Unix()),
+	}
 
-		// If the task was for an access point device
-		if r.Descriptor.JType == "ap" {
-			accessPointResults = append(accessPointResults, deviceResult)
-		}
+	// Discard the state of the previous scan
+	m.accessPointResults = nil
+	m.subscriberModuleResults = nil
+	m.pendingValues = m.pendingValues[:0]
+	m.pendingRates = m.pendingRates[:0]
+	m.failures = nil
+	m.storedValues = 0
+	m.storedRates = 0
 
-		// If the task was for a subscriber module
-		if r.Descriptor.JType == "sm" {
-			subscriberModuleResults = append(subscriberModuleResults, deviceResult)
-		}
-	case <-wp.Done:
-		// Handles the case where the worker pool has finished executing all tasks
-		//logging.Warning("Worker pool has finished executing all tasks.")
-		if !createCSVExport() {
-			logging.Error("Failed to create CSV exports.")
+	success, jobs := m.setupJobs()
+
+	if success != true {
+		return false, summary
+	}
+
+	pool := workers.NewWithLimits[snmp.PollRequest, map[string]interface{}](m.appConfig.Workers, workers.Limits{
+		PacketsPerSecond: m.appConfig.PacketsPerSecond,
+		GroupConcurrency: m.appConfig.SubnetConcurrency,
+	})
+	poolCtx, cancel := context.WithCancel(ctx)
+
+	defer cancel()
+
+	logging.Debug("Starting %v workers to process %v SNMP jobs.", m.appConfig.Workers, len(jobs))
+
+	queued := make(map[workers.JobID]scanJob, len(jobs))
+
+	for _, job := range jobs {
+		queued[job.Descriptor.ID] = job
+	}
+
+	go func() {
+		for _, job := range jobs {
+			if !pool.Submit(poolCtx, job) {
+				return
+			}
 		}
-		return false
-	default:
-		// Handles the case where the worker pool is still executing tasks
-		//logging.Debug("Worker pool is still executing tasks.")
+	}()
+
+	// The pool is closed once no job is left to finish, including the jobs that are waiting to be retried
+	pending := len(jobs)
+
+	if pending == 0 {
+		pool.Close()
 	}
 
-	return true
-}
+	go pool.Run(poolCtx)
 
-func SetupTaskManager() bool {
-	SetupWorkerPool()
+	// The results channel is closed once every worker has stopped
+	for result := range pool.Results() {
+		job := queued[result.Descriptor.ID]
+		job.Descriptor = result.Descriptor
 
-	// Creates jobs in the queue
-	SetupJobs()
+		if result.Err != nil && m.retryJob(poolCtx, pool, job, result.Err) {
+			summary.Retries++
+			continue
+		}
 
-	// Loads the job queue into the worker pool
-	LoadJobs()
+		if m.handleResult(result) {
+			summary.Completed++
 
-	// Signals the worker pool to begin execution of the job queue
-	StartJobs()
+			if result.Descriptor.Retries > 0 {
+				summary.Recovered++
+			}
+		} else {
+			summary.Failed++
+		}
 
-	return true
-}
+		pending--
 
-func SetupWorkerPool() context.CancelFunc {
-	logging.Debug("Setting up new worker pool with %v workers.", config.AppConfig.Workers)
+		if pending == 0 {
+			pool.Close()
+		}
+	}
 
-	var cancel context.CancelFunc
+	summary.Jobs = len(jobs)
+	summary.Skipped = summary.Jobs - summary.Completed - summary.Failed
+	summary.Status = snmp.ScanStatusComplete
 
-	// Create worker pool
-	wp = workers.New(config.AppConfig.Workers)
+	// The values collected before the scan was canceled are still exported and stored, as a partial scan
+	if ctx.Err() != nil {
+		summary.Status = snmp.ScanStatusPartial
+		logging.Warning("Scan was canceled; storing the results of %v of %v jobs.",
+			summary.Completed+summary.Failed, summary.Jobs)
+	}
 
-	ctx, cancel = context.WithCancel(context.TODO())
+	if !m.createCSVExport() {
+		logging.Error("Failed to create CSV exports.")
+	}
 
-	return cancel
-}
+	if !m.finishScan(summary.Status) {
+		logging.Error("Failed to store SNMP scan results.")
+	}
 
-func SetupJobs() {
+	summary.ScanId = m.scan.Id
+	summary.Failures = make(map[string]int)
 
-	logging.Info("Setting up jobs for workers...")
+	for _, failure := range m.failures {
+		summary.Failures[failure.Reason]++
+	}
+	summary.StoredValues = m.storedValues
+	summary.StoredRates = m.storedRates
+	summary.Finished = int(time.Now().Unix())
+
+	logging.Info("Scan finished; id: %v; jobs: %v; completed: %v; failed: %v; skipped: %v; retries: %v; "+
+		"recovered: %v; values: %v; rates: %v; duration: %vs;", summary.ScanId, summary.Jobs, summary.Completed,
+		summary.Failed, summary.Skipped, summary.Retries, summary.Recovered, summary.StoredValues, summary.StoredRates,
+		summary.Finished-summary.Started)
+
+	if len(summary.Failures) > 0 {
+		logging.Info("Scan failures; unreachable: %v; timeout: %v; auth: %v; partial: %v; error: %v;",
+			summary.Failures[snmp.FailureUnreachable], summary.Failures[snmp.FailureTimeout],
+			summary.Failures[snmp.FailureAuth], summary.Failures[snmp.FailurePartial], summary.Failures[snmp.FailureError])
+	}
 
-	logging.Debug("Loading existing inventory records from database...")
+	return true, summary
+}
 
-	// Synchronize changes from the database
-	syncDatabase()
+// handleResult processes the final result of a single device job, returning whether the job succeeded and collected
+// any values. The values collected by jobs that failed are still stored, but their poll groups aren't marked as polled
+// so that they're polled again by the next scan.
+func (m *TaskManager) handleResult(result scanResult) bool {
+	values := result.Value
+
+	polled := int(time.Now().Unix())
+	groups, _ := result.Descriptor.Metadata["groups"].([]string)
+
+	switch result.Descriptor.JType {
+	case "ap":
+		record := result.Descriptor.Metadata["record"].(device.AccessPoint)
+		rates := m.queueDeviceValues(snmp.DeviceTypeAccessPoint, record.Id, m.accessPointOidMaps, values)
+		m.accessPointResults = append(m.accessPointResults, deviceResult{
+			DeviceId:    record.Id,
+			IPv4Address: record.IPv4Address,
+			Values:      values,
+			Rates:       rates,
+		})
+
+		if result.Err != nil {
+			m.recordFailure(snmp.DeviceTypeAccessPoint, record.Id, record.IPv4Address, result)
+		} else if len(values) > 0 {
+			m.markPolled(snmp.DeviceTypeAccessPoint, record.Id, groups, polled)
+		}
 
-	db := database.GetConnection(database.ConnectionMap.CamScan)
+		m.updateRegistrations(record, values)
+	case "sm":
+		record := result.Descriptor.Metadata["record"].(device.SubscriberModule)
+		rates := m.queueDeviceValues(snmp.DeviceTypeSubscriberModule, record.Id, m.subscriberModuleOidMaps, values)
+		m.subscriberModuleResults = append(m.subscriberModuleResults, deviceResult{
+			DeviceId:    record.Id,
+			IPv4Address: record.IPv4Address,
+			Values:      values,
+			Rates:       rates,
+		})
+
+		if result.Err != nil {
+			m.recordFailure(snmp.DeviceTypeSubscriberModule, record.Id, record.IPv4Address, result)
+		} else if len(values) > 0 {
+			m.markPolled(snmp.DeviceTypeSubscriberModule, record.Id, groups, polled)
+		}
+	}
 
-	// dbSubnet.PopulateSubnets(db)
+	return len(values) > 0 && result.Err == nil
+}
 
-	// Build jobs queue for device ICMP checks
-	jobId := 1
-	// _, jobId, jobs = networkApi.BuildDeviceCheckJobs(db, appConfig, 1)
+func (m *TaskManager) setupJobs() (bool, []scanJob) {
+	jobs := make([]scanJob, 0)
 
-	_, accessPointOidMaps = dbOm.GetRecords(db, snmp.DeviceTypeAccessPoint)
-	_, subscriberModuleOidMaps = dbOm.GetRecords(db, snmp.DeviceTypeSubscriberModule)
+	logging.Info("Setting up jobs for workers...")
 
-	accessPointOids = make(map[string]string)
-	subscriberModuleOids = make(map[string]string)
+	logging.Debug("Loading existing inventory records from database...")
 
-	for _, el := range accessPointOidMaps {
-		accessPointOids[el.KeyName] = el.Oid
+	// Synchronize changes from the database
+	if !m.syncDatabase() {
+		logging.Error("Failed to load inventory records from database.")
+		return false, jobs
 	}
 
-	for _, el := range subscriberModuleOidMaps {
-		subscriberModuleOids[el.KeyName] = el.Oid
+	// Create the scan run that collected values will be stored under
+	if !m.startScan() {
+		logging.Error("Failed to create SNMP scan record; collected values won't be stored.")
 	}
 
-	for key, _ := range accessPointOids {
-		accessPointCSV += key + ","
+	// Load the poll groups so that only the OIDs whose poll group is due are polled
+	if !m.loadPollSchedule() {
+		logging.Error("Failed to load SNMP poll groups.")
+		return false, jobs
 	}
 
-	for key, _ := range subscriberModuleOids {
-		subscriberModuleCSV += key + ","
+	// Load the previous counter samples that rates are computed from
+	if !m.loadCounterSamples() {
+		logging.Error("Failed to load SNMP counter samples.")
+		return false, jobs
 	}
 
-	accessPointCSV = accessPointCSV[:len(accessPointCSV)-1] + "\n"
-	subscriberModuleCSV = subscriberModuleCSV[:len(subscriberModuleCSV)-1] + "\n"
+	jobId := 1
+	now := int(time.Now().Unix())
 
 	// Load jobs queue with access points
-	for _, el := range accessPoints {
+	for _, el := range m.accessPoints {
 		if el.Status < 1 {
 			continue
 		}
 
+		due, groups := m.dueOidMaps(snmp.DeviceTypeAccessPoint, el.Id, m.accessPointOidMaps, now)
+
+		if len(due) == 0 {
+			logging.Trace1("Skipping ap without due poll groups; id: %v; ip: %s;", el.Id, el.IPv4Address)
+			continue
+		}
+
+		request := buildPollRequest(due)
+
 		metadata := make(map[string]interface{})
 		metadata["record"] = el
-		metadata["oids"] = accessPointOids
+		metadata["groups"] = groups
 
-		job := workers.Job{
+		job := scanJob{
 			Descriptor: workers.JobDescriptor{
 				ID:        workers.JobID(fmt.Sprintf("%v", jobId)),
 				JType:     "ap",
-				AppConfig: config.AppConfig,
+				AppConfig: m.appConfig,
 				Metadata:  metadata,
-				Db:        db,
+				Storage:   m.storage,
+				Timeout:   time.Duration(1000000000 * m.appConfig.JobTimeout),
 			},
 			ExecFn: ap.ScanDevice,
-			Args:   jobId,
+			Args:   request,
 		}
 
 		logging.Debug("Queueing job for ap (%v); id: %v; nid: %v; mac: %s; ip: %s; status: %v;",
@@ -181,119 +319,238 @@ func SetupJobs() {
 		jobId++
 	}
 
-	// Load jobs queue with subscriber modules
-	for _, el := range subscriberModules {
+	// Load jobs queue with subscriber modules, which are grouped by access point, or by subnet while their access point
+	// is unknown, so that each sector is only polled a few subscriber modules at a time
+	subscriberModuleJobs := make([]scanJob, 0)
+
+	for _, el := range m.subscriberModules {
 		if el.Status < 1 {
 			continue
 		}
 
+		due, groups := m.dueOidMaps(snmp.DeviceTypeSubscriberModule, el.Id, m.subscriberModuleOidMaps, now)
+
+		if len(due) == 0 {
+			logging.Trace1("Skipping sm without due poll groups; id: %v; ip: %s;", el.Id, el.IPv4Address)
+			continue
+		}
+
+		request := buildPollRequest(due)
+
 		metadata := make(map[string]interface{})
 		metadata["record"] = el
-		metadata["oids"] = subscriberModuleOids
+		metadata["groups"] = groups
 
-		job := workers.Job{
+		job := scanJob{
 			Descriptor: workers.JobDescriptor{
 				ID:        workers.JobID(fmt.Sprintf("%v", jobId)),
 				JType:     "sm",
-				AppConfig: config.AppConfig,
+				AppConfig: m.appConfig,
 				Metadata:  metadata,
-				Db:        db,
+				Storage:   m.storage,
+				Timeout:   time.Duration(1000000000 * m.appConfig.JobTimeout),
+				Group:     scanGroup(m.subnets, m.registrations, el),
 			},
 			ExecFn: sm.ScanDevice,
-			Args:   jobId,
+			Args:   request,
 		}
 
-		logging.Debug("Queueing job for sm (%v); id: %v; nid: %v; mac: %s; ip: %s; status: %v;",
-			job.Descriptor.ID, el.Id, el.NetworkId, el.MacAddress, el.IPv4Address, el.Status)
+		logging.Debug("Queueing job for sm (%v); id: %v; nid: %v; mac: %s; ip: %s; status: %v; group: %s;",
+			job.Descriptor.ID, el.Id, el.NetworkId, el.MacAddress, el.IPv4Address, el.Status, job.Descriptor.Group)
 
-		jobs = append(jobs, job)
+		subscriberModuleJobs = append(subscriberModuleJobs, job)
 		jobId++
 	}
-}
 
-func LoadJobs() {
-	logging.Debug("Loading %v jobs into the worker pool task queue.", len(jobs))
-	go wp.GenerateFrom(jobs)
+	jobs = append(jobs, interleaveJobs(subscriberModuleJobs)...)
+
+	return true, jobs
 }
 
-func StartJobs() {
-	logging.Debug("Starting %v workers to process SNMP queries.", config.AppConfig.Workers)
-	go wp.Run(ctx)
+// buildPollRequest separates the scalar OIDs, which are retrieved with a single get request, from the tables, which are
+// walked, along with the transforms that their values are normalized with
+func buildPollRequest(oidMaps []snmp.OidMap) snmp.PollRequest {
+	request := snmp.PollRequest{
+		Oids:       make(map[string]string),
+		Tables:     make(map[string]string),
+		Transforms: make(map[string]snmp.Transform),
+	}
+
+	for _, el := range oidMaps {
+		if el.Kind == snmp.OidKindTable {
+			request.Tables[el.KeyName] = el.Oid
+		} else {
+			request.Oids[el.KeyName] = el.Oid
+		}
+
+		if !el.Transform.IsEmpty() {
+			request.Transforms[el.KeyName] = el.Transform
+		}
+	}
+
+	return request
 }
 
-func syncDatabase() bool {
-	// Open a fresh database connection to ensure a smooth execution
-	success, db := database.CreateConnection(database.ConnectionMap.CamScan, config.AppConfig.DbConfig)
+// syncDatabase reloads the inventory and OID maps so that every scan picks up new devices and OID map changes
+func (m *TaskManager) syncDatabase() bool {
+	m.storage = openStorage(m.appConfig.DbConfig)
+
+	if m.storage == nil {
+		return false
+	}
+
+	success, accessPoints := m.storage.AccessPoints.GetRecords()
 
-	// Handle any exceptions that may have occurred when attempting to open the database connection
 	if success != true {
 		return false
 	}
 
-	success, subnets = dbSubnet.GetRecords(db)
+	success, subscriberModules := m.storage.SubscriberModules.GetRecords()
 
 	if success != true {
 		return false
 	}
 
-	success, accessPoints = dbAp.GetRecords(db)
+	success, subnets := m.storage.Subnets.GetRecords()
 
 	if success != true {
 		return false
 	}
 
-	success, subscriberModules = dbSm.GetRecords(db)
+	success, registrations := m.storage.Registrations.GetRecords()
 
 	if success != true {
 		return false
 	}
 
+	success, accessPointOidMaps := m.storage.OidMaps.GetRecords(snmp.DeviceTypeAccessPoint)
+
+	if success != true {
+		return false
+	}
+
+	success, subscriberModuleOidMaps := m.storage.OidMaps.GetRecords(snmp.DeviceTypeSubscriberModule)
+
+	if success != true {
+		return false
+	}
+
+	if m.mibs == nil && !m.LoadMibs() {
+		return false
+	}
+
+	// Symbolic OIDs are resolved using the MIB files, which also validates every OID of the map
+	if !resolveOidMaps(m.mibs, accessPointOidMaps) || !resolveOidMaps(m.mibs, subscriberModuleOidMaps) {
+		return false
+	}
+
+	m.accessPoints = accessPoints
+	m.subscriberModules = subscriberModules
+	m.subnets = subnets
+	m.subscriberModuleIds = make(map[string]int, len(subscriberModules))
+	m.registrations = make(map[int]int, len(registrations))
+
+	for _, record := range subscriberModules {
+		if record.MacAddress != device.UnknownMacAddress {
+			m.subscriberModuleIds[record.MacAddress] = record.Id
+		}
+	}
+
+	for _, record := range registrations {
+		m.registrations[record.SubscriberModuleId] = record.AccessPointId
+	}
+	m.accessPointOidMaps = accessPointOidMaps
+	m.subscriberModuleOidMaps = subscriberModuleOidMaps
+
+	m.accessPointTables = buildPollRequest(accessPointOidMaps).Tables
+	m.subscriberModuleTables = buildPollRequest(subscriberModuleOidMaps).Tables
+
 	return true
 }
 
-func createCSVExport() bool {
+func (m *TaskManager) createCSVExport() bool {
 	// Setup CSV headers for each device type
 
 	// Access Point Headers
 	apHeader := make([]string, 0)
 	apRows := make([][]string, 0)
-	for _, om := range accessPointOidMaps {
+	for _, om := range m.accessPointOidMaps {
+		if om.Kind == snmp.OidKindTable {
+			continue
+		}
 		apHeader = append(apHeader, om.KeyName)
 	}
+	apRateKeys := scalarRateKeys(m.accessPointOidMaps, m.accessPointResults)
+	for _, key := range apRateKeys {
+		apHeader = append(apHeader, key+"_rate")
+	}
+	apBootKeys := scalarBootKeys(m.accessPointOidMaps, m.accessPointResults)
+	for _, key := range apBootKeys {
+		apHeader = append(apHeader, key+"_last_boot")
+	}
 	apRows = append(apRows, apHeader)
 
 	// Subscriber Module Headers
 	smHeader := make([]string, 0)
 	smRows := make([][]string, 0)
-	for _, om := range subscriberModuleOidMaps {
+	for _, om := range m.subscriberModuleOidMaps {
+		if om.Kind == snmp.OidKindTable {
+			continue
+		}
 		smHeader = append(smHeader, om.KeyName)
 	}
+	smRateKeys := scalarRateKeys(m.subscriberModuleOidMaps, m.subscriberModuleResults)
+	for _, key := range smRateKeys {
+		smHeader = append(smHeader, key+"_rate")
+	}
+	smBootKeys := scalarBootKeys(m.subscriberModuleOidMaps, m.subscriberModuleResults)
+	for _, key := range smBootKeys {
+		smHeader = append(smHeader, key+"_last_boot")
+	}
 	smRows = append(smRows, smHeader)
 
 	// Process Access Point Results
-	for _, accessPointResult := range accessPointResults {
+	for _, accessPointResult := range m.accessPointResults {
 		apRow := make([]string, 0)
-		for _, om := range accessPointOidMaps {
-			result, ok := accessPointResult[om.KeyName]
+		for _, om := range m.accessPointOidMaps {
+			if om.Kind == snmp.OidKindTable {
+				continue
+			}
+			result, ok := accessPointResult.Values[om.KeyName]
 			if !ok {
 				apRow = append(apRow, "")
 				continue
 			}
-			apRow = append(apRow, fmt.Sprintf("%v", result))
+			apRow = append(apRow, formatValue(m.mibs, om.Oid, result))
+		}
+		for _, key := range apRateKeys {
+			apRow = append(apRow, formatRate(accessPointResult.Rates, key, ""))
+		}
+		for _, key := range apBootKeys {
+			apRow = append(apRow, formatLastBoot(accessPointResult.Values[key]))
 		}
 		apRows = append(apRows, apRow)
 	}
 
 	// Process Subscriber Module Results
-	for _, subscriberModuleResult := range subscriberModuleResults {
+	for _, subscriberModuleResult := range m.subscriberModuleResults {
 		smRow := make([]string, 0)
-		for _, om := range subscriberModuleOidMaps {
-			result, ok := subscriberModuleResult[om.KeyName]
+		for _, om := range m.subscriberModuleOidMaps {
+			if om.Kind == snmp.OidKindTable {
+				continue
+			}
+			result, ok := subscriberModuleResult.Values[om.KeyName]
 			if !ok {
 				smRow = append(smRow, "")
 				continue
 			}
-			smRow = append(smRow, fmt.Sprintf("%v", result))
+			smRow = append(smRow, formatValue(m.mibs, om.Oid, result))
+		}
+		for _, key := range smRateKeys {
+			smRow = append(smRow, formatRate(subscriberModuleResult.Rates, key, ""))
+		}
+		for _, key := range smBootKeys {
+			smRow = append(smRow, formatLastBoot(subscriberModuleResult.Values[key]))
 		}
 		smRows = append(smRows, smRow)
 	}
@@ -353,5 +610,211 @@ func createCSVExport() bool {
 	apWriter.Flush()
 	smWriter.Flush()
 
+	// Export the rows of every walked table into a separate file for each table
+	for key, oid := range m.accessPointTables {
+		if !createTableCSVExport("/tmp/ap_"+key+".csv", key, oid, m.mibs, m.accessPointResults) {
+			failed = true
+		}
+	}
+
+	for key, oid := range m.subscriberModuleTables {
+		if !createTableCSVExport("/tmp/sm_"+key+".csv", key, oid, m.mibs, m.subscriberModuleResults) {
+			failed = true
+		}
+	}
+
 	return !failed
 }
+
+func createTableCSVExport(filePath string, key string, oid string, mibs *mib.Tree, results []deviceResult) bool {
+	// Collect the columns returned by any device so every row shares the same header
+	columnSet := make(map[string]bool)
+	rateColumnSet := make(map[string]bool)
+	bootColumnSet := make(map[string]bool)
+
+	for _, result := range results {
+		table, ok := result.Values[key].(snmp.Table)
+		if !ok {
+			continue
+		}
+		for index, row := range table {
+			for column, cell := range row {
+				columnSet[column] = true
+				if value, ok := cell.(snmp.Value); ok && value.SnmpType == snmp.TypeTimeTicks {
+					bootColumnSet[column] = true
+				}
+				if _, ok := result.Rates[key][column+"."+index]; ok {
+					rateColumnSet[column] = true
+				}
+			}
+		}
+	}
+
+	columns := make([]string, 0, len(columnSet))
+	for column := range columnSet {
+		columns = append(columns, column)
+	}
+	sortOidComponents(columns)
+
+	rateColumns := make([]string, 0, len(rateColumnSet))
+	for column := range rateColumnSet {
+		rateColumns = append(rateColumns, column)
+	}
+	sortOidComponents(rateColumns)
+
+	bootColumns := make([]string, 0, len(bootColumnSet))
+	for column := range bootColumnSet {
+		bootColumns = append(bootColumns, column)
+	}
+	sortOidComponents(bootColumns)
+
+	header := []string{"device_id", "ipv4_address", "index"}
+	for _, column := range columns {
+		header = append(header, key+"."+column)
+	}
+	for _, column := range rateColumns {
+		header = append(header, key+"."+column+"_rate")
+	}
+	for _, column := range bootColumns {
+		header = append(header, key+"."+column+"_last_boot")
+	}
+
+	rows := [][]string{header}
+
+	for _, result := range results {
+		table, ok := result.Values[key].(snmp.Table)
+		if !ok {
+			continue
+		}
+
+		indexes := make([]string, 0, len(table))
+		for index := range table {
+			indexes = append(indexes, index)
+		}
+		sortOidComponents(indexes)
+
+		for _, index := range indexes {
+			row := []string{strconv.Itoa(result.DeviceId), result.IPv4Address, index}
+			for _, column := range columns {
+				value, ok := table[index][column]
+				if !ok {
+					row = append(row, "")
+					continue
+				}
+				row = append(row, formatValue(mibs, oid+"."+column+"."+index, value))
+			}
+			for _, column := range rateColumns {
+				row = append(row, formatRate(result.Rates, key, column+"."+index))
+			}
+			for _, column := range bootColumns {
+				row = append(row, formatLastBoot(table[index][column]))
+			}
+			rows = append(rows, row)
+		}
+	}
+
+	file, err := os.Create(filePath)
+
+	if err != nil {
+		logging.Error("Failed to create table CSV file; path: %s; error: %s;", filePath, err.Error())
+		return false
+	}
+
+	defer func(file *os.File) {
+		_ = file.Close()
+	}(file)
+
+	writer := csv.NewWriter(file)
+
+	if err = writer.WriteAll(rows); err != nil {
+		logging.Error("Failed to write table CSV rows; path: %s; error: %s;", filePath, err.Error())
+		return false
+	}
+
+	return true
+}
+
+// scalarRateKeys returns the key names of the scalar OIDs that a rate was computed for on any device
+func scalarRateKeys(oidMaps []snmp.OidMap, results []deviceResult) []string {
+	keys := make([]string, 0)
+
+	for _, om := range oidMaps {
+		if om.Kind == snmp.OidKindTable {
+			continue
+		}
+		for _, result := range results {
+			if _, ok := result.Rates[om.KeyName][""]; ok {
+				keys = append(keys, om.KeyName)
+				break
+			}
+		}
+	}
+
+	return keys
+}
+
+// scalarBootKeys returns the key names of the scalar OIDs that any device returned a TimeTicks value for
+func scalarBootKeys(oidMaps []snmp.OidMap, results []deviceResult) []string {
+	keys := make([]string, 0)
+
+	for _, om := range oidMaps {
+		if om.Kind == snmp.OidKindTable {
+			continue
+		}
+		for _, result := range results {
+			if value, ok := result.Values[om.KeyName].(snmp.Value); ok && value.SnmpType == snmp.TypeTimeTicks {
+				keys = append(keys, om.KeyName)
+				break
+			}
+		}
+	}
+
+	return keys
+}
+
+// formatLastBoot formats the last boot of a TimeTicks value, or returns an empty string for any other value
+func formatLastBoot(result interface{}) string {
+	value, ok := result.(snmp.Value)
+
+	if !ok {
+		return ""
+	}
+
+	return value.FormatLastBoot()
+}
+
+// formatRate formats the rate computed for the given key name and OID index, or an empty string when there's none
+func formatRate(rates deviceRates, key string, index string) string {
+	rate, ok := rates[key][index]
+
+	if !ok {
+		return ""
+	}
+
+	return strconv.FormatFloat(rate, 'f', 2, 64)
+}
+
+// sortOidComponents sorts dotted OID components like "2.10" numerically rather than lexically
+func sortOidComponents(values []string) {
+	sort.Slice(values, func(i, j int) bool {
+		a := strings.Split(values[i], ".")
+		b := strings.Split(values[j], ".")
+
+		for k := 0; k < len(a) && k < len(b); k++ {
+			if a[k] == b[k] {
+				continue
+			}
+
+			x, errX := strconv.Atoi(a[k])
+			y, errY := strconv.Atoi(b[k])
+
+			if errX != nil || errY != nil {
+				return a[k] < b[k]
+			}
+
+			return x < y
+		}
+
+		return len(a) < len(b)
+	})
+}
@@ -0,0 +1,332 @@
+package tasks
+
+import (
+	"as/camscan/internal/camscan/database/repository"
+	"as/camscan/internal/camscan/logging"
+	"as/camscan/internal/camscan/mib"
+	snmpApi "as/camscan/internal/camscan/snmp"
+	"as/camscan/internal/camscan/types"
+	"as/camscan/internal/camscan/types/snmp"
+	"context"
+	"encoding/csv"
+	"github.com/gosnmp/gosnmp"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const eventsFilePath = "/tmp/events.csv"
+
+// TrapManager receives the traps and informs that access points and subscriber modules send, e.g. when a subscriber
+// module registers or when GPS sync is lost, and stores them as events of the device that sent them.
+type TrapManager struct {
+	appConfig   types.AppConfig
+	storage     *repository.Repositories
+	mibs        *mib.Tree
+	oidMaps     map[int][]snmp.OidMap
+	communities map[string]bool
+	received    int
+	stored      int
+	ignored     int
+}
+
+func NewTrapManager(appConfig types.AppConfig) *TrapManager {
+	return &TrapManager{
+		appConfig: appConfig,
+		oidMaps:   make(map[int][]snmp.OidMap),
+	}
+}
+
+// Run listens for traps and informs on the configured address until the given context is canceled. The OID maps and
+// MIB files that the values of events are named with are loaded once, when the listener starts.
+func (m *TrapManager) Run(ctx context.Context) bool {
+	if !m.setup() {
+		return false
+	}
+
+	m.loadCommunities()
+
+	listener, err := snmpApi.NewTrapListener(m.trapCredential())
+
+	if err != nil {
+		logging.Error("Failed to create SNMP trap listener; error: %s;", err.Error())
+		return false
+	}
+
+	listener.OnNewTrap = m.handleTrap
+
+	stopped := make(chan error, 1)
+
+	go func() {
+		stopped <- listener.Listen(m.appConfig.TrapAddress)
+	}()
+
+	select {
+	case err = <-stopped:
+		logging.Error("Failed to listen for SNMP traps; address: %s; error: %v;", m.appConfig.TrapAddress, err)
+		return false
+	case <-listener.Listening():
+	}
+
+	logging.Info("Listening for SNMP traps; address: %s;", m.appConfig.TrapAddress)
+
+	select {
+	case <-ctx.Done():
+		listener.Close()
+		<-stopped
+	case err = <-stopped:
+		logging.Error("SNMP trap listener stopped unexpectedly; address: %s; error: %v;",
+			m.appConfig.TrapAddress, err)
+		return false
+	}
+
+	logging.Info("Stopped listening for SNMP traps; received: %v; stored: %v; ignored: %v;",
+		m.received, m.stored, m.ignored)
+
+	return true
+}
+
+// Export writes the events received within the given duration before now to a CSV file, next to the CSV files of the
+// scans
+func (m *TrapManager) Export(since time.Duration) bool {
+	if !m.setup() {
+		return false
+	}
+
+	to := int(time.Now().Unix())
+	from := to - int(since/time.Second)
+
+	success, events := m.storage.Events.GetRecordsByRange(from, to)
+
+	if success != true {
+		return false
+	}
+
+	rows := [][]string{{"received", "device_type", "device_id", "source_address", "version", "pdu_type", "trap_oid",
+		"trap_name", "uptime", "values"}}
+
+	for _, event := range events {
+		values := make([]string, 0, len(event.Values))
+
+		for _, value := range event.Values {
+			values = append(values, value.Name+"="+formatValue(m.mibs, value.Oid, value.Value))
+		}
+
+		rows = append(rows, []string{
+			time.Unix(int64(event.Received), 0).UTC().Format(time.RFC3339),
+			deviceTypeLabel(event.DeviceType),
+			strconv.Itoa(event.DeviceId),
+			event.SourceAddress,
+			event.Version,
+			pduTypeLabel(event.PduType),
+			event.TrapOid,
+			event.TrapName,
+			snmp.FormatDuration(snmp.Value{SnmpValueNum: float64(event.Uptime)}.Duration()),
+			strings.Join(values, "; "),
+		})
+	}
+
+	file, err := os.Create(eventsFilePath)
+
+	if err != nil {
+		logging.Error("Failed to create event CSV file; path: %s; error: %s;", eventsFilePath, err.Error())
+		return false
+	}
+
+	defer func(file *os.File) {
+		_ = file.Close()
+	}(file)
+
+	writer := csv.NewWriter(file)
+
+	if err = writer.WriteAll(rows); err != nil {
+		logging.Error("Failed to write event CSV rows; path: %s; error: %s;", eventsFilePath, err.Error())
+		return false
+	}
+
+	logging.Info("Exported SNMP events; path: %s; events: %v; since: %v;", eventsFilePath, len(events), since)
+
+	return true
+}
+
+// setup loads the MIB files, opens the database and loads the OID maps of both device types
+func (m *TrapManager) setup() bool {
+	success, tree := loadMibs(m.appConfig.MibDir)
+
+	if success != true {
+		return false
+	}
+
+	m.mibs = tree
+	m.storage = openStorage(m.appConfig.DbConfig)
+
+	if m.storage == nil {
+		return false
+	}
+
+	for _, deviceType := range []int{snmp.DeviceTypeAccessPoint, snmp.DeviceTypeSubscriberModule} {
+		success, oidMaps := m.storage.OidMaps.GetRecords(deviceType)
+
+		if success != true || !resolveOidMaps(m.mibs, oidMaps) {
+			return false
+		}
+
+		m.oidMaps[deviceType] = oidMaps
+	}
+
+	return true
+}
+
+// loadCommunities determines the communities that SNMPv1 and SNMPv2c notifications are accepted from, which are the
+// configured trap communities or, when there are none, every community used for polling
+func (m *TrapManager) loadCommunities() {
+	m.communities = make(map[string]bool)
+
+	if len(m.appConfig.TrapCommunities) > 0 {
+		for _, community := range m.appConfig.TrapCommunities {
+			m.communities[community] = true
+		}
+
+		return
+	}
+
+	m.communities[m.appConfig.Community] = true
+	m.communities[m.appConfig.SnmpApCommunity] = true
+	m.communities[m.appConfig.SnmpSmCommunity] = true
+
+	for _, credential := range m.appConfig.SnmpCredentials {
+		if credential.Version != snmp.Version3 {
+			m.communities[credential.Community] = true
+		}
+	}
+
+	for _, credential := range m.appConfig.SnmpNetworks {
+		if credential.Community != "" {
+			m.communities[credential.Community] = true
+		}
+	}
+}
+
+// trapCredential returns the credential that SNMPv3 notifications are authenticated with, which is the global SNMPv3
+// user. Without a user, only SNMPv1 and SNMPv2c notifications are received.
+func (m *TrapManager) trapCredential() snmp.Credential {
+	if m.appConfig.SnmpV3User == "" {
+		return snmp.Credential{Name: "trap", Version: snmp.Version2c}
+	}
+
+	return snmp.Credential{
+		Name:           "trap",
+		Version:        snmp.Version3,
+		User:           m.appConfig.SnmpV3User,
+		AuthProtocol:   m.appConfig.SnmpV3AuthProtocol,
+		AuthPassphrase: m.appConfig.SnmpV3AuthPassphrase,
+		PrivProtocol:   m.appConfig.SnmpV3PrivProtocol,
+		PrivPassphrase: m.appConfig.SnmpV3PrivPassphrase,
+	}
+}
+
+// handleTrap stores a received trap or inform as an event of the device that sent it. Informs are acknowledged by the
+// listener once they're handled, including those that are ignored because of their community.
+func (m *TrapManager) handleTrap(packet *gosnmp.SnmpPacket, addr *net.UDPAddr) {
+	m.received++
+
+	source := addr.IP.String()
+
+	if packet.Version != gosnmp.Version3 && !m.communities[packet.Community] {
+		m.ignored++
+		logging.Warning("Ignoring SNMP trap with an unknown community; source: %s; version: %s;",
+			source, packet.Version.String())
+		return
+	}
+
+	event := snmpApi.DecodeTrap(packet)
+	event.SourceAddress = source
+	event.Received = int(time.Now().Unix())
+
+	event.DeviceType, event.DeviceId = m.findDevice(source)
+
+	// SNMPv1 traps forwarded on behalf of a device carry the address of the device that raised them
+	if event.DeviceId == 0 && packet.AgentAddress != "" && packet.AgentAddress != source {
+		event.DeviceType, event.DeviceId = m.findDevice(packet.AgentAddress)
+	}
+
+	if name := m.mibs.Name(event.TrapOid); name != event.TrapOid {
+		event.TrapName = name
+	}
+
+	for i := range event.Values {
+		m.nameValue(event.DeviceType, &event.Values[i])
+	}
+
+	logging.Info("Received SNMP %s; source: %s; type: %v; did: %v; trap: %s; name: %s; values: %v;",
+		pduTypeLabel(event.PduType), source, event.DeviceType, event.DeviceId, event.TrapOid, event.TrapName,
+		len(event.Values))
+
+	if event.DeviceId == 0 {
+		logging.Debug("SNMP trap source isn't a known device; source: %s; agent: %s;", source, packet.AgentAddress)
+	}
+
+	if m.appConfig.DryRun {
+		return
+	}
+
+	if success, _ := m.storage.Events.InsertRecord(event); success == true {
+		m.stored++
+	}
+}
+
+// findDevice returns the type and ID of the access point or subscriber module with the given address, or zeros when
+// the address doesn't belong to a known device
+func (m *TrapManager) findDevice(ipv4 string) (int, int) {
+	if success, record := m.storage.AccessPoints.GetRecordByIPv4Address(ipv4); success == true {
+		return snmp.DeviceTypeAccessPoint, record.Id
+	}
+
+	if success, record := m.storage.SubscriberModules.GetRecordByIPv4Address(ipv4); success == true {
+		return snmp.DeviceTypeSubscriberModule, record.Id
+	}
+
+	return 0, 0
+}
+
+// nameValue names an event value after the OID map entry of the device's type that it belongs to, where the values of
+// table entries are named like "key.column.index", and normalizes it with the entry's transform. Values that no entry
+// maps are named after the MIB object that defines them.
+func (m *TrapManager) nameValue(deviceType int, value *snmp.EventValue) {
+	for _, om := range m.oidMaps[deviceType] {
+		switch {
+		case om.Kind != snmp.OidKindTable && om.Oid == value.Oid:
+			value.Name = om.KeyName
+		case om.Kind == snmp.OidKindTable && strings.HasPrefix(value.Oid, om.Oid+"."):
+			value.Name = om.KeyName + strings.TrimPrefix(value.Oid, om.Oid)
+		default:
+			continue
+		}
+
+		value.Value = om.Transform.Apply(value.Value)
+		return
+	}
+
+	value.Name = m.mibs.Name(value.Oid)
+}
+
+func deviceTypeLabel(deviceType int) string {
+	switch deviceType {
+	case snmp.DeviceTypeAccessPoint:
+		return "ap"
+	case snmp.DeviceTypeSubscriberModule:
+		return "sm"
+	}
+
+	return ""
+}
+
+func pduTypeLabel(pduType int) string {
+	if pduType == snmp.PduTypeInform {
+		return "inform"
+	}
+
+	return "trap"
+}
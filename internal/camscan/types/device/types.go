@@ -1,11 +1,19 @@
 package device
 
+const UnknownMacAddress = "000000000000"
+
+const SnmpStatusUnknown = 0
+const SnmpStatusOk = 1
+const SnmpStatusNoCredential = 2
+
 type AccessPoint struct {
 	Id             int
 	NetworkId      int
 	MacAddress     string
 	IPv4Address    string
 	IPv4AddressInt uint32
+	SnmpCredential string
+	SnmpStatus     int
 	Status         int
 }
 
@@ -15,5 +23,26 @@ type SubscriberModule struct {
 	MacAddress     string
 	IPv4Address    string
 	IPv4AddressInt uint32
+	SnmpCredential string
+	SnmpStatus     int
 	Status         int
 }
+
+// Registration associates a subscriber module with the access point that it's registered to
+type Registration struct {
+	SubscriberModuleId int
+	AccessPointId      int
+	Updated            int
+}
+
+type AddressChange struct {
+	Id                int
+	DeviceType        int
+	DeviceId          int
+	MacAddress        string
+	OldIPv4Address    string
+	OldIPv4AddressInt uint32
+	NewIPv4Address    string
+	NewIPv4AddressInt uint32
+	Changed           int
+}
@@ -25,3 +25,27 @@ type Device struct {
 	IPv4AddressInt uint32
 	Status         int
 }
+
+const DiscoveryModeAccessPoint = "ap"
+const DiscoveryModeSubscriberModule = "sm"
+const DiscoveryModeUnknown = "unknown"
+
+const DiscoveryOutcomeNew = "new"
+const DiscoveryOutcomeChanged = "changed"
+const DiscoveryOutcomeUnchanged = "unchanged"
+const DiscoveryOutcomeUnclassified = "unclassified"
+const DiscoveryOutcomeUnresponsive = "unresponsive"
+
+type DiscoveryResult struct {
+	Device         Device
+	MacAddress     string
+	Mode           string
+	Outcome        string
+	SnmpCredential string
+}
+
+// DiscoverySummary counts the checked addresses by discovery mode and discovery outcome
+type DiscoverySummary struct {
+	Addresses int
+	Outcomes  map[string]map[string]int
+}
@@ -0,0 +1,142 @@
+package snmp
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Transform normalizes the values of an OID map entry. Pattern is a regular expression that extracts its first
+// capture group, or the whole match, from text values, which become numbers when the extracted text is numeric.
+// Numbers are then multiplied by Scale, where zero leaves them unscaled, and Offset is added. Enum maps numbers to
+// labels as comma separated number=label pairs, e.g. "1=QPSK,2=16-QAM", and Unit tags the values with a unit label.
+type Transform struct {
+	Scale   float64
+	Offset  float64
+	Pattern string
+	Enum    string
+	Unit    string
+	pattern *regexp.Regexp
+	labels  map[string]string
+}
+
+// Compile validates the pattern and enum labels of the transform, preparing them to be applied
+func (t *Transform) Compile() error {
+	t.pattern = nil
+	t.labels = nil
+
+	if t.Pattern != "" {
+		pattern, err := regexp.Compile(t.Pattern)
+
+		if err != nil {
+			return fmt.Errorf("invalid pattern '%s'; %w", t.Pattern, err)
+		}
+
+		t.pattern = pattern
+	}
+
+	if strings.TrimSpace(t.Enum) != "" {
+		t.labels = make(map[string]string)
+
+		for _, pair := range strings.Split(t.Enum, ",") {
+			number, label, ok := strings.Cut(pair, "=")
+			number = strings.TrimSpace(number)
+			label = strings.TrimSpace(label)
+
+			if !ok || label == "" {
+				return fmt.Errorf("invalid enum label '%s'; expected number=label", strings.TrimSpace(pair))
+			}
+
+			parsed, err := strconv.ParseFloat(number, 64)
+
+			if err != nil {
+				return fmt.Errorf("invalid enum number '%s'", number)
+			}
+
+			t.labels[formatNumber(parsed)] = label
+		}
+	}
+
+	return nil
+}
+
+// IsEmpty determines whether the transform leaves values unchanged
+func (t Transform) IsEmpty() bool {
+	return t.Factor() == 1 && t.Offset == 0 && t.Pattern == "" && strings.TrimSpace(t.Enum) == "" && t.Unit == ""
+}
+
+// Factor returns the number that values are multiplied by
+func (t Transform) Factor() float64 {
+	if t.Scale == 0 {
+		return 1
+	}
+
+	return t.Scale
+}
+
+// Apply returns the normalized form of the given value. Text that the pattern doesn't match is left unchanged.
+func (t Transform) Apply(value Value) Value {
+	if t.pattern != nil && !value.IsNumeric() {
+		text := value.SnmpValueChar + value.SnmpValueText
+
+		if match := t.pattern.FindStringSubmatch(text); match != nil {
+			extracted := match[0]
+
+			if len(match) > 1 {
+				extracted = match[1]
+			}
+
+			extracted = strings.TrimSpace(extracted)
+
+			// Extracted numbers are stored as doubles so that they're scaled and stored like any other number
+			if number, err := strconv.ParseFloat(extracted, 64); err == nil {
+				value.SnmpType = TypeOpaqueDouble
+				value.SnmpValueNum = number
+				value.SnmpValueChar = ""
+				value.SnmpValueText = ""
+			} else {
+				value.SnmpValueChar = ""
+				value.SnmpValueText = ""
+
+				if len(extracted) > MaxValueCharLength {
+					value.SnmpValueText = extracted
+				} else {
+					value.SnmpValueChar = extracted
+				}
+			}
+		}
+	}
+
+	if value.IsNumeric() {
+		if t.Factor() != 1 || t.Offset != 0 {
+			value.SnmpValueNum = value.SnmpValueNum*t.Factor() + t.Offset
+
+			// The exact text of Counter64 values no longer matches the scaled number
+			value.SnmpValueChar = ""
+		}
+
+		if label, ok := t.labels[formatNumber(value.SnmpValueNum)]; ok {
+			value.SnmpValueChar = label
+		}
+	}
+
+	value.Unit = t.Unit
+
+	return value
+}
+
+// ApplyTable normalizes every cell of the given table in place
+func (t Transform) ApplyTable(table Table) {
+	for _, row := range table {
+		for column, cell := range row {
+			if value, ok := cell.(Value); ok {
+				row[column] = t.Apply(value)
+			}
+		}
+	}
+}
+
+func formatNumber(number float64) string {
+	return strconv.FormatFloat(number, 'f', -1, 64)
+}
@@ -1,24 +1,266 @@
 package snmp
 
+import (
+	"fmt"
+	"strconv"
+	"time"
+)
+
 const DeviceTypeAccessPoint = 1
 const DeviceTypeSubscriberModule = 2
 
+// SNMP value types as identified by their ASN.1 BER tags
+const TypeInteger = 0x02
+const TypeOctetString = 0x04
+const TypeObjectIdentifier = 0x06
+const TypeIPAddress = 0x40
+const TypeCounter32 = 0x41
+const TypeGauge32 = 0x42
+const TypeTimeTicks = 0x43
+const TypeOpaque = 0x44
+const TypeCounter64 = 0x46
+const TypeUinteger32 = 0x47
+const TypeOpaqueFloat = 0x78
+const TypeOpaqueDouble = 0x79
+
+// Text values longer than this are stored in SnmpValueText rather than SnmpValueChar
+const MaxValueCharLength = 255
+
+const OidKindScalar = "scalar"
+const OidKindTable = "table"
+
+const Version1 = "1"
+const Version2c = "2c"
+const Version3 = "3"
+
+type Credential struct {
+	Name           string
+	Version        string
+	Community      string
+	User           string
+	AuthProtocol   string
+	AuthPassphrase string
+	PrivProtocol   string
+	PrivPassphrase string
+}
+
 type OidMap struct {
 	Id         int
 	DeviceType int
 	KeyName    string
 	Oid        string
+	Kind       string
 	Order      int
+	PollGroup  string
+	Transform  Transform
 }
 
+// PollGroup defines how often the OIDs that belong to the group are polled. An interval of zero polls the group every
+// scan cycle, like OIDs that don't belong to a group.
+type PollGroup struct {
+	Id       int
+	Name     string
+	Interval int
+}
+
+// PollRequest lists what a scan job polls on a single device, where Oids and Tables map key names to the OIDs of
+// scalars and table entries respectively, and Transforms maps key names to the transforms their values are normalized
+// with
+type PollRequest struct {
+	Oids       map[string]string
+	Tables     map[string]string
+	Transforms map[string]Transform
+}
+
+// TableRow holds the values of a single table row keyed by column number
+type TableRow map[string]interface{}
+
+// Table holds the rows returned by an SNMP table walk keyed by row index
+type Table map[string]TableRow
+
+const ScanStatusRunning = 1
+const ScanStatusComplete = 2
+const ScanStatusPartial = 3
+
+type Scan struct {
+	Id       int
+	Started  int
+	Finished int
+	Status   int
+}
+
+// ScanSummary describes the outcome of a single scan, where Completed counts the jobs that collected values, Failed
+// the jobs that didn't and Skipped the jobs that never ran because the scan was canceled. Retries counts every retry of
+// a failed job, Recovered the jobs that completed after being retried and Failures the failed jobs by failure reason.
+// StoredValues and StoredRates count the values and counter rates stored for the scan.
+type ScanSummary struct {
+	ScanId       int
+	Status       int
+	Started      int
+	Finished     int
+	Jobs         int
+	Completed    int
+	Failed       int
+	Skipped      int
+	Retries      int
+	Recovered    int
+	Failures     map[string]int
+	StoredValues int
+	StoredRates  int
+}
+
+// Reasons why a device couldn't be polled, recorded once every attempt to poll the device has failed
+const FailureUnreachable = "unreachable"
+const FailureTimeout = "timeout"
+const FailureAuth = "auth"
+const FailurePartial = "partial"
+const FailureError = "error"
+
+type PollFailure struct {
+	Id         int
+	ScanId     int
+	DeviceType int
+	DeviceId   int
+	Reason     string
+	Attempts   int
+	Message    string
+	Recorded   int
+}
+
+// Value is a single value collected from a device. TimeTicks values hold the number of hundredths of a second in
+// SnmpValueNum, and LastBoot holds the unix timestamp that the duration started at, which for uptime OIDs like
+// sysUpTime is when the device last booted. Unit is the unit label of the OID map entry's transform.
 type Value struct {
 	Id            int
+	ScanId        int
 	DeviceType    int
 	DeviceId      int
 	OidMapId      int
+	OidIndex      string
 	SnmpType      int
 	SnmpValueChar string
 	SnmpValueNum  float64
 	SnmpValueText string
+	Unit          string
+	LastBoot      int
 	Captured      int
 }
+
+// IsCounter determines whether the value is a counter whose rate of change is more meaningful than its total
+func (v Value) IsCounter() bool {
+	return v.SnmpType == TypeCounter32 || v.SnmpType == TypeCounter64
+}
+
+// Rate is the per-second rate of change of a counter between two consecutive samples taken Period seconds apart
+type Rate struct {
+	Id         int
+	ScanId     int
+	DeviceType int
+	DeviceId   int
+	OidMapId   int
+	OidIndex   string
+	Rate       float64
+	Period     int
+	Captured   int
+}
+
+// SNMP notification PDU types as identified by their ASN.1 BER tags
+const PduTypeTrapV1 = 0xa4
+const PduTypeInform = 0xa6
+const PduTypeTrap = 0xa7
+
+// Event is a trap or inform received from a device. DeviceType and DeviceId are zero when the source address doesn't
+// belong to a known device. TrapOid identifies the notification, TrapName is its symbolic name when a loaded MIB
+// defines it and Uptime is the sender's sysUpTime in hundredths of a second. Received is the unix timestamp that the
+// event was received at.
+type Event struct {
+	Id            int
+	DeviceType    int
+	DeviceId      int
+	SourceAddress string
+	Version       string
+	PduType       int
+	TrapOid       string
+	TrapName      string
+	Uptime        int
+	Received      int
+	Values        []EventValue
+}
+
+// EventValue is a single variable binding of an event, named by its OID map key or by the MIB object that defines it
+type EventValue struct {
+	Id      int
+	EventId int
+	Oid     string
+	Name    string
+	Value   Value
+}
+
+// IsNumeric determines whether the value is stored in SnmpValueNum
+func (v Value) IsNumeric() bool {
+	switch v.SnmpType {
+	case TypeInteger, TypeCounter32, TypeGauge32, TypeTimeTicks, TypeCounter64, TypeUinteger32, TypeOpaqueFloat,
+		TypeOpaqueDouble:
+		return true
+	}
+	return false
+}
+
+// Duration converts a TimeTicks value into the duration it represents
+func (v Value) Duration() time.Duration {
+	return time.Duration(v.SnmpValueNum) * 10 * time.Millisecond
+}
+
+// FormatDuration renders a duration in days, hours, minutes and seconds, e.g. "12d 3h 4m 5s", leaving out the leading
+// units that are zero
+func FormatDuration(duration time.Duration) string {
+	seconds := int64(duration / time.Second)
+	days := seconds / 86400
+	hours := seconds / 3600 % 24
+	minutes := seconds / 60 % 60
+	seconds = seconds % 60
+
+	switch {
+	case days > 0:
+		return fmt.Sprintf("%dd %dh %dm %ds", days, hours, minutes, seconds)
+	case hours > 0:
+		return fmt.Sprintf("%dh %dm %ds", hours, minutes, seconds)
+	case minutes > 0:
+		return fmt.Sprintf("%dm %ds", minutes, seconds)
+	}
+
+	return fmt.Sprintf("%ds", seconds)
+}
+
+// FormatLastBoot renders the LastBoot timestamp of a TimeTicks value as an RFC 3339 date and time in UTC
+func (v Value) FormatLastBoot() string {
+	if v.SnmpType != TypeTimeTicks {
+		return ""
+	}
+
+	return time.Unix(int64(v.LastBoot), 0).UTC().Format(time.RFC3339)
+}
+
+func (v Value) String() string {
+	// TimeTicks values are rendered as a readable duration rather than hundredths of a second
+	if v.SnmpType == TypeTimeTicks {
+		return FormatDuration(v.Duration())
+	}
+
+	// Counter64 values keep their exact text since they can exceed the precision of SnmpValueNum, while other numbers
+	// may hold an enum label
+	text := v.SnmpValueChar + v.SnmpValueText
+
+	if text == "" && v.IsNumeric() {
+		text = strconv.FormatFloat(v.SnmpValueNum, 'f', -1, 64)
+	}
+
+	// Only numbers are tagged with their unit, rather than enum labels
+	if v.Unit != "" && v.IsNumeric() {
+		if _, err := strconv.ParseFloat(text, 64); err == nil {
+			return text + " " + v.Unit
+		}
+	}
+
+	return text
+}
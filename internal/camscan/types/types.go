@@ -1,30 +1,66 @@
 package types
 
+import (
+	"as/camscan/internal/camscan/types/snmp"
+	"time"
+)
+
 type AppConfig struct {
-	Community       string
-	DbConfig        DbConfig
-	Debug           bool
-	DryRun          bool
-	ICMPRetries     int
-	ICMPTimeout     float64
-	LogLevel        int
-	SnmpApCommunity string
-	SnmpSmCommunity string
-	SnmpTimeoutAp   float64
-	SnmpTimeoutSm   float64
-	Workers         int
+	Community            string
+	Daemon               bool
+	DbConfig             DbConfig
+	Debug                bool
+	DryRun               bool
+	ICMPRetries          int
+	ICMPTimeout          float64
+	JobTimeout           float64
+	LogLevel             int
+	MibDir               string
+	PacketsPerSecond     int
+	RetryAttempts        int
+	RetryBackoff         time.Duration
+	ScanInterval         time.Duration
+	SnmpApCommunity      string
+	SnmpCredentials      []snmp.Credential
+	SnmpMaxOids          int
+	SnmpNetworks         map[int]snmp.Credential
+	SnmpSmCommunity      string
+	SnmpTimeoutAp        float64
+	SnmpTimeoutSm        float64
+	SnmpV3AuthPassphrase string
+	SnmpV3AuthProtocol   string
+	SnmpV3PrivPassphrase string
+	SnmpV3PrivProtocol   string
+	SnmpV3User           string
+	SnmpVersion          string
+	SubnetConcurrency    int
+	TrapAddress          string
+	TrapCommunities      []string
+	Workers              int
 }
 
 type DbConfig struct {
+	BatchSize         int
+	Driver            string
 	Host              string
 	Name              string
 	Password          string
+	Path              string
 	Port              string
+	SslMode           string
 	User              string
 	ConnectRetries    int
 	ConnectRetryDelay int
+	SkipSchemaCheck   bool
 }
 
 type DbConnections struct {
 	CamScan string
 }
+
+type SchemaMigration struct {
+	Version int
+	Name    string
+	File    string
+	Applied int
+}
@@ -0,0 +1,40 @@
+package workers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// TimeoutError is returned by jobs that stopped before finishing, either because the job's deadline expired or because
+// the worker pool was canceled. Err is context.DeadlineExceeded or context.Canceled respectively.
+type TimeoutError struct {
+	JobID JobID
+	Host  string
+	Err   error
+}
+
+func (e *TimeoutError) Error() string {
+	if errors.Is(e.Err, context.Canceled) {
+		return fmt.Sprintf("job %s for %s was canceled", e.JobID, e.Host)
+	}
+
+	return fmt.Sprintf("job %s for %s exceeded its deadline", e.JobID, e.Host)
+}
+
+func (e *TimeoutError) Unwrap() error {
+	return e.Err
+}
+
+// CheckDeadline returns a TimeoutError for the given job when the given context is done, and nil otherwise
+func CheckDeadline(ctx context.Context, descriptor JobDescriptor, host string) error {
+	if ctx.Err() == nil {
+		return nil
+	}
+
+	return &TimeoutError{
+		JobID: descriptor.ID,
+		Host:  host,
+		Err:   ctx.Err(),
+	}
+}
@@ -1,48 +1,77 @@
 package workers
 
 import (
+	"as/camscan/internal/camscan/database/repository"
 	"as/camscan/internal/camscan/types"
 	"context"
-	"database/sql"
+	"fmt"
+	"runtime/debug"
+	"time"
 )
 
 type JobID string
 type JobType string
 type JobMetadata map[string]interface{}
 
-type ExecutionFn func(ctx context.Context, args interface{}, descriptor JobDescriptor) (interface{}, error)
+type ExecutionFn[Args any, Out any] func(ctx context.Context, args Args, descriptor JobDescriptor) (Out, error)
 
 type JobDescriptor struct {
 	ID        JobID
 	JType     JobType
 	AppConfig types.AppConfig
 	Metadata  map[string]interface{}
-	Db        *sql.DB
+	Storage   *repository.Repositories
+	Timeout   time.Duration
+	Retries   int
+	Group     string
 }
 
-type Result struct {
-	Value      interface{}
+type Result[Out any] struct {
+	Value      Out
 	Err        error
 	Descriptor JobDescriptor
 }
 
-type Job struct {
+type Job[Args any, Out any] struct {
 	Descriptor JobDescriptor
-	ExecFn     ExecutionFn
-	Args       interface{}
+	ExecFn     ExecutionFn[Args, Out]
+	Args       Args
 }
 
-func (j Job) execute(ctx context.Context) Result {
-	value, err := j.ExecFn(ctx, j.Args, j.Descriptor)
-	if err != nil {
-		return Result{
-			Err:        err,
-			Descriptor: j.Descriptor,
+// PanicError is returned for jobs that panicked, along with the stack trace of the goroutine at the time of the panic
+type PanicError struct {
+	JobID JobID
+	Value interface{}
+	Stack []byte
+}
+
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("job %s panicked: %v\n%s", e.JobID, e.Value, e.Stack)
+}
+
+// execute runs the job with a context that expires after the job's timeout, when it has one. The value is kept along
+// with any error since jobs that run out of time still return what they collected until then. A panic only fails the
+// job that raised it.
+func (j Job[Args, Out]) execute(ctx context.Context) (result Result[Out]) {
+	result.Descriptor = j.Descriptor
+
+	defer func() {
+		if value := recover(); value != nil {
+			result.Err = &PanicError{
+				JobID: j.Descriptor.ID,
+				Value: value,
+				Stack: debug.Stack(),
+			}
 		}
-	}
+	}()
 
-	return Result{
-		Value:      value,
-		Descriptor: j.Descriptor,
+	if j.Descriptor.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, j.Descriptor.Timeout)
+		defer cancel()
 	}
+
+	result.Value, result.Err = j.ExecFn(ctx, j.Args, j.Descriptor)
+
+	return result
 }
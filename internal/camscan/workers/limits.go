@@ -0,0 +1,129 @@
+package workers
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Limits restricts how hard the jobs of a pool may hit the network, where zero values mean no limit. PacketsPerSecond
+// applies to every packet sent by every job of the pool, while GroupConcurrency caps the number of jobs of the same
+// group, see JobDescriptor.Group, that execute at the same time.
+type Limits struct {
+	PacketsPerSecond int
+	GroupConcurrency int
+}
+
+type rateLimiterKey struct{}
+
+// RateLimiter spaces out events evenly so that no more than the configured number of events happen per second
+type RateLimiter struct {
+	interval time.Duration
+	mutex    sync.Mutex
+	next     time.Time
+}
+
+func NewRateLimiter(perSecond int) *RateLimiter {
+	if perSecond < 1 {
+		return nil
+	}
+
+	return &RateLimiter{
+		interval: time.Second / time.Duration(perSecond),
+	}
+}
+
+// Wait blocks until the next event is allowed, returning the context's error when the given context is done first
+func (l *RateLimiter) Wait(ctx context.Context) error {
+	if l == nil {
+		return nil
+	}
+
+	l.mutex.Lock()
+	now := time.Now()
+
+	if l.next.Before(now) {
+		l.next = now
+	}
+
+	wait := l.next.Sub(now)
+	l.next = l.next.Add(l.interval)
+	l.mutex.Unlock()
+
+	if wait <= 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// WaitForPacket blocks until the rate limiter of the pool executing the job of the given context allows another packet
+// to be sent. It returns immediately for pools without a packet rate limit.
+func WaitForPacket(ctx context.Context) error {
+	limiter, _ := ctx.Value(rateLimiterKey{}).(*RateLimiter)
+
+	return limiter.Wait(ctx)
+}
+
+// groupLimiter caps the number of jobs of the same group that execute at the same time
+type groupLimiter struct {
+	concurrency int
+	mutex       sync.Mutex
+	slots       map[string]chan struct{}
+}
+
+func newGroupLimiter(concurrency int) *groupLimiter {
+	if concurrency < 1 {
+		return nil
+	}
+
+	return &groupLimiter{
+		concurrency: concurrency,
+		slots:       make(map[string]chan struct{}),
+	}
+}
+
+// tryAcquire takes a slot of the given group without waiting, returning false when the group has no free slot. Jobs
+// without a group always get one.
+func (l *groupLimiter) tryAcquire(group string) bool {
+	if l == nil || group == "" {
+		return true
+	}
+
+	l.mutex.Lock()
+	slots, ok := l.slots[group]
+
+	if !ok {
+		slots = make(chan struct{}, l.concurrency)
+		l.slots[group] = slots
+	}
+
+	l.mutex.Unlock()
+
+	select {
+	case slots <- struct{}{}:
+		return true
+	default:
+		return false
+	}
+}
+
+func (l *groupLimiter) release(group string) {
+	if l == nil || group == "" {
+		return
+	}
+
+	l.mutex.Lock()
+	slots := l.slots[group]
+	l.mutex.Unlock()
+
+	<-slots
+}
@@ -6,51 +6,140 @@ import (
 	"sync"
 )
 
-func worker(ctx context.Context, wg *sync.WaitGroup, jobs <-chan Job, results chan<- Result) {
+// WorkerPool executes jobs that take arguments of type Args and produce values of type Out using a fixed number of
+// workers.
+type WorkerPool[Args any, Out any] struct {
+	workersCount int
+	jobs         chan Job[Args, Out]
+	ready        chan Job[Args, Out]
+	released     chan struct{}
+	results      chan Result[Out]
+	limiter      *RateLimiter
+	groups       *groupLimiter
+	Done         chan struct{}
+}
+
+func New[Args any, Out any](wcount int) WorkerPool[Args, Out] {
+	return NewWithLimits[Args, Out](wcount, Limits{})
+}
+
+// NewWithLimits creates a worker pool whose jobs are restricted by the given limits
+func NewWithLimits[Args any, Out any](wcount int, limits Limits) WorkerPool[Args, Out] {
+	return WorkerPool[Args, Out]{
+		workersCount: wcount,
+		jobs:         make(chan Job[Args, Out], wcount),
+		ready:        make(chan Job[Args, Out]),
+		released:     make(chan struct{}, 1),
+		results:      make(chan Result[Out], wcount),
+		limiter:      NewRateLimiter(limits.PacketsPerSecond),
+		groups:       newGroupLimiter(limits.GroupConcurrency),
+		Done:         make(chan struct{}),
+	}
+}
+
+func (wp WorkerPool[Args, Out]) worker(ctx context.Context, wg *sync.WaitGroup) {
 	defer wg.Done()
+
+	// Jobs find the pool's packet rate limiter through their context
+	jobCtx := ctx
+
+	if wp.limiter != nil {
+		jobCtx = context.WithValue(ctx, rateLimiterKey{}, wp.limiter)
+	}
+
 	for {
+		// Stop picking up jobs once the context is canceled, the job being executed is always allowed to finish
+		if ctx.Err() != nil {
+			logging.Debug("Worker canceled; error: %v;", ctx.Err())
+			return
+		}
+
 		select {
-		case job, ok := <-jobs:
+		case job, ok := <-wp.ready:
 			if !ok {
 				return
 			}
+
+			// The dispatcher acquired the job's group slot before handing the job over
+			result := job.execute(jobCtx)
+			wp.groups.release(job.Descriptor.Group)
+
+			select {
+			case wp.released <- struct{}{}:
+			default:
+			}
+
 			// fan-in job execution multiplexing results into the results channel
-			results <- job.execute(ctx)
+			wp.results <- result
 		case <-ctx.Done():
-			logging.Error("Worker Canceled: %v;\n", ctx.Err())
-			results <- Result{
-				Err: ctx.Err(),
-			}
+			logging.Debug("Worker canceled; error: %v;", ctx.Err())
 			return
 		}
 	}
 }
 
-type WorkerPool struct {
-	workersCount int
-	jobs         chan Job
-	results      chan Result
-	Done         chan struct{}
-}
+// dispatch hands the submitted jobs to the workers, holding back the jobs of groups that have reached their
+// concurrency limit so that the workers pick up the jobs of other groups meanwhile instead of waiting for a slot.
+// Held back jobs are handed out in submission order as soon as a job of their group finishes.
+func (wp WorkerPool[Args, Out]) dispatch(ctx context.Context) {
+	defer close(wp.ready)
 
-func New(wcount int) WorkerPool {
-	return WorkerPool{
-		workersCount: wcount,
-		jobs:         make(chan Job, wcount),
-		results:      make(chan Result, wcount),
-		Done:         make(chan struct{}),
+	jobs := wp.jobs
+	held := make([]Job[Args, Out], 0)
+
+	for {
+		next := -1
+
+		for i := range held {
+			if wp.groups.tryAcquire(held[i].Descriptor.Group) {
+				next = i
+				break
+			}
+		}
+
+		if next >= 0 {
+			select {
+			case wp.ready <- held[next]:
+				held = append(held[:next], held[next+1:]...)
+			case <-ctx.Done():
+				wp.groups.release(held[next].Descriptor.Group)
+				return
+			}
+
+			continue
+		}
+
+		if jobs == nil && len(held) == 0 {
+			return
+		}
+
+		select {
+		case job, ok := <-jobs:
+			if !ok {
+				// No more jobs are submitted, but the held back ones are still handed out
+				jobs = nil
+				continue
+			}
+
+			held = append(held, job)
+		case <-wp.released:
+		case <-ctx.Done():
+			return
+		}
 	}
 }
 
-func (wp WorkerPool) Run(ctx context.Context) {
+func (wp WorkerPool[Args, Out]) Run(ctx context.Context) {
 	var wg sync.WaitGroup
 
+	go wp.dispatch(ctx)
+
 	for i := 0; i < wp.workersCount; i++ {
 		wg.Add(1)
 		// fan out worker goroutines
 		//reading from jobs channel and
 		//pushing calcs into results channel
-		go worker(ctx, &wg, wp.jobs, wp.results)
+		go wp.worker(ctx, &wg)
 	}
 
 	wg.Wait()
@@ -58,13 +147,36 @@ func (wp WorkerPool) Run(ctx context.Context) {
 	close(wp.results)
 }
 
-func (wp WorkerPool) Results() <-chan Result {
+func (wp WorkerPool[Args, Out]) Results() <-chan Result[Out] {
 	return wp.results
 }
 
-func (wp WorkerPool) GenerateFrom(jobsBulk []Job) {
-	for i := range jobsBulk {
-		wp.jobs <- jobsBulk[i]
+// Submit queues a single job for the workers, returning false without queueing the job when the given context is
+// canceled first.
+func (wp WorkerPool[Args, Out]) Submit(ctx context.Context, job Job[Args, Out]) bool {
+	select {
+	case wp.jobs <- job:
+		return true
+	case <-ctx.Done():
+		return false
 	}
+}
+
+// Close signals that no more jobs will be submitted, letting the workers stop once every queued job has executed.
+func (wp WorkerPool[Args, Out]) Close() {
 	close(wp.jobs)
 }
+
+// GenerateFrom queues the given jobs for the workers, stopping early when the given context is canceled since the
+// workers no longer pick up jobs by then.
+func (wp WorkerPool[Args, Out]) GenerateFrom(ctx context.Context, jobsBulk []Job[Args, Out]) {
+	defer close(wp.jobs)
+
+	for i := range jobsBulk {
+		select {
+		case wp.jobs <- jobsBulk[i]:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
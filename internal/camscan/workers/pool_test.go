@@ -0,0 +1,87 @@
+package workers
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestPoolRunsOtherGroupsWhileOneIsFull(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	pool := NewWithLimits[string, string](2, Limits{GroupConcurrency: 1})
+	unblock := make(chan struct{})
+
+	blocking := func(ctx context.Context, args string, _ JobDescriptor) (string, error) {
+		select {
+		case <-unblock:
+		case <-ctx.Done():
+		}
+
+		return args, ctx.Err()
+	}
+
+	instant := func(_ context.Context, args string, _ JobDescriptor) (string, error) {
+		return args, nil
+	}
+
+	go pool.Run(ctx)
+
+	// Both workers are free, but the second job of group a has to wait for the first one, so the job of group b must
+	// run on the other worker rather than queueing behind it
+	pool.Submit(ctx, Job[string, string]{Descriptor: JobDescriptor{ID: "a1", Group: "a"}, ExecFn: blocking, Args: "a1"})
+	pool.Submit(ctx, Job[string, string]{Descriptor: JobDescriptor{ID: "a2", Group: "a"}, ExecFn: instant, Args: "a2"})
+	pool.Submit(ctx, Job[string, string]{Descriptor: JobDescriptor{ID: "b1", Group: "b"}, ExecFn: instant, Args: "b1"})
+	pool.Close()
+
+	select {
+	case result := <-pool.Results():
+		if result.Value != "b1" {
+			t.Fatalf("first result = %s, want b1", result.Value)
+		}
+	case <-ctx.Done():
+		t.Fatal("job of a free group did not run while another group was full")
+	}
+
+	close(unblock)
+	order := make([]string, 0, 2)
+
+	for result := range pool.Results() {
+		order = append(order, result.Value)
+	}
+
+	if len(order) != 2 || order[0] != "a1" || order[1] != "a2" {
+		t.Errorf("remaining results = %v, want [a1 a2]", order)
+	}
+}
+
+func TestPoolStopsHandingOutJobsWhenCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	pool := NewWithLimits[int, int](1, Limits{GroupConcurrency: 1})
+	started := make(chan struct{})
+
+	job := func(ctx context.Context, args int, _ JobDescriptor) (int, error) {
+		started <- struct{}{}
+		<-ctx.Done()
+		return args, ctx.Err()
+	}
+
+	go pool.Run(ctx)
+
+	pool.Submit(ctx, Job[int, int]{Descriptor: JobDescriptor{ID: "1", Group: "a"}, ExecFn: job, Args: 1})
+	pool.Submit(ctx, Job[int, int]{Descriptor: JobDescriptor{ID: "2", Group: "a"}, ExecFn: job, Args: 2})
+
+	<-started
+	cancel()
+
+	results := 0
+
+	for range pool.Results() {
+		results++
+	}
+
+	if results != 1 {
+		t.Errorf("results = %v, want only the job that was executing", results)
+	}
+}